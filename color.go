@@ -0,0 +1,124 @@
+package ebitencm
+
+import (
+	"math"
+
+	"github.com/setanarut/cm"
+)
+
+// ColorMode selects how DrawSpace picks the fill color of awake dynamic bodies
+type ColorMode int
+
+const (
+	// ColorModeTheme fills all dynamic bodies with Theme.DynamicBodyFill
+	ColorModeTheme ColorMode = iota
+	// ColorModeHash fills each dynamic body with a stable pastel color like the Chipmunk demos
+	ColorModeHash
+	// ColorModeSpeed samples Theme.Gradient by the linear speed of the body
+	ColorModeSpeed
+	// ColorModeAngularSpeed samples Theme.Gradient by the absolute angular velocity of the body
+	ColorModeAngularSpeed
+	// ColorModeMass samples Theme.Gradient by the mass of the body
+	ColorModeMass
+	// ColorModeKineticEnergy samples Theme.Gradient by the kinetic energy of the body
+	ColorModeKineticEnergy
+)
+
+// bodyProperty returns the body property sampled by the gradient color mode
+func bodyProperty(body *cm.Body, mode ColorMode) float64 {
+	switch mode {
+	case ColorModeSpeed:
+		return body.Velocity().Mag()
+	case ColorModeAngularSpeed:
+		return math.Abs(body.AngularVelocity())
+	case ColorModeMass:
+		return body.Mass()
+	case ColorModeKineticEnergy:
+		return body.KineticEnergy()
+	default:
+		return 0
+	}
+}
+
+// updateGradientRange sets the property range of the gradient color modes for this frame
+func (d *Drawer) updateGradientRange(space *cm.Space) {
+	mode := d.Theme.DynamicBodyColorMode
+	if mode < ColorModeSpeed {
+		return
+	}
+	if !d.Theme.GradientAutoRange {
+		d.gradientMin, d.gradientMax = d.Theme.GradientMin, d.Theme.GradientMax
+		return
+	}
+	d.gradientMin, d.gradientMax = math.Inf(1), math.Inf(-1)
+	for _, body := range space.DynamicBodies {
+		// kinematic bodies have infinite mass and kinetic energy
+		if body.IsSleeping() || body.Type() != cm.Dynamic {
+			continue
+		}
+		p := bodyProperty(body, mode)
+		if math.IsNaN(p) || math.IsInf(p, 0) {
+			continue
+		}
+		d.gradientMin = min(d.gradientMin, p)
+		d.gradientMax = max(d.gradientMax, p)
+	}
+}
+
+// dynamicBodyFill returns the fill color of the awake dynamic body for the Theme color mode.
+// Kinematic bodies use Theme.DynamicBodyFill.
+func (d *Drawer) dynamicBodyFill(body *cm.Body) cm.FColor {
+	var c cm.FColor
+	switch mode := d.Theme.DynamicBodyColorMode; {
+	case mode == ColorModeTheme, body.Type() != cm.Dynamic:
+		return d.Theme.DynamicBodyFill
+	case mode == ColorModeHash:
+		c = HashColor(body)
+	default:
+		t := 0.0
+		if d.gradientMax > d.gradientMin {
+			t = (bodyProperty(body, mode) - d.gradientMin) / (d.gradientMax - d.gradientMin)
+		}
+		c = d.Theme.Gradient.At(t)
+	}
+	c.A = d.Theme.DynamicBodyFill.A
+	return c
+}
+
+// HashColor returns a stable pseudo-random color for the body.
+//
+// The color is computed from the hash id of the first shape of the body,
+// so it only changes when the body's shapes are re-added to the space.
+func HashColor(body *cm.Body) cm.FColor {
+	var val uint32
+	if len(body.Shapes) > 0 {
+		val = uint32(body.Shapes[0].HashId())
+	}
+
+	// scramble the bits up using Robert Jenkins' 32 bit integer hash function
+	val = (val + 0x7ed55d16) + (val << 12)
+	val = (val ^ 0xc761c23c) ^ (val >> 19)
+	val = (val + 0x165667b1) + (val << 5)
+	val = (val + 0xd3a2646c) ^ (val << 9)
+	val = (val + 0xfd7046c5) + (val << 3)
+	val = (val ^ 0xb55a4f09) ^ (val >> 16)
+
+	r := float32((val >> 0) & 0xFF)
+	g := float32((val >> 8) & 0xFF)
+	b := float32((val >> 16) & 0xFF)
+
+	maxc := max(r, g, b)
+	minc := min(r, g, b)
+
+	intensity := float32(0.75)
+	if body.Type() == cm.Static {
+		intensity = 0.15
+	}
+
+	// Saturate and scale the color
+	if minc == maxc {
+		return cm.FColor{R: intensity, A: 1}
+	}
+	coef := intensity / (maxc - minc)
+	return cm.FColor{R: (r - minc) * coef, G: (g - minc) * coef, B: (b - minc) * coef, A: 1}
+}
@@ -0,0 +1,269 @@
+package ebitencm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/setanarut/cm"
+)
+
+// namedColors are the color names accepted by ParseColor
+var namedColors = map[string]cm.FColor{
+	"transparent": {},
+	"black":       {R: 0, G: 0, B: 0, A: 1},
+	"white":       {R: 1, G: 1, B: 1, A: 1},
+	"gray":        {R: 0.5, G: 0.5, B: 0.5, A: 1},
+	"red":         {R: 1, G: 0, B: 0, A: 1},
+	"green":       {R: 0, G: 1, B: 0, A: 1},
+	"blue":        {R: 0, G: 0, B: 1, A: 1},
+	"yellow":      {R: 1, G: 1, B: 0, A: 1},
+	"cyan":        {R: 0, G: 1, B: 1, A: 1},
+	"magenta":     {R: 1, G: 0, B: 1, A: 1},
+	"orange":      {R: 1, G: 0.647, B: 0, A: 1},
+	"purple":      {R: 0.5, G: 0, B: 0.5, A: 1},
+	"pink":        {R: 1, G: 0.753, B: 0.796, A: 1},
+}
+
+// ParseColor parses a "#rgb", "#rrggbb", "#rrggbbaa" or named color
+func ParseColor(s string) (cm.FColor, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if c, ok := namedColors[s]; ok {
+		return c, nil
+	}
+	hex, ok := strings.CutPrefix(s, "#")
+	if !ok {
+		return cm.FColor{}, fmt.Errorf("ebitencm: invalid color %q", s)
+	}
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	if len(hex) != 8 {
+		return cm.FColor{}, fmt.Errorf("ebitencm: invalid color %q", s)
+	}
+	n, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return cm.FColor{}, fmt.Errorf("ebitencm: invalid color %q", s)
+	}
+	return cm.FColor{
+		R: float32(n>>24&0xff) / 255,
+		G: float32(n>>16&0xff) / 255,
+		B: float32(n>>8&0xff) / 255,
+		A: float32(n&0xff) / 255,
+	}, nil
+}
+
+// HexColor formats the color as "#rrggbbaa"
+func HexColor(c cm.FColor) string {
+	b := func(f float32) uint8 {
+		return uint8(math.Round(float64(min(max(f, 0), 1)) * 255))
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", b(c.R), b(c.G), b(c.B), b(c.A))
+}
+
+// hexColor is a cm.FColor encoded as a color string in JSON
+type hexColor cm.FColor
+
+func (c hexColor) MarshalJSON() ([]byte, error) {
+	return json.Marshal(HexColor(cm.FColor(c)))
+}
+
+func (c *hexColor) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return json.Unmarshal(data, (*cm.FColor)(c))
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	clr, err := ParseColor(s)
+	if err != nil {
+		return err
+	}
+	*c = hexColor(clr)
+	return nil
+}
+
+// gradientStopJSON is the JSON form of GradientStop
+type gradientStopJSON struct {
+	Offset float64
+	Color  hexColor
+}
+
+func (s GradientStop) MarshalJSON() ([]byte, error) {
+	return json.Marshal(gradientStopJSON{s.Offset, hexColor(s.Color)})
+}
+
+func (s *GradientStop) UnmarshalJSON(data []byte) error {
+	var j gradientStopJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	s.Offset, s.Color = j.Offset, cm.FColor(j.Color)
+	return nil
+}
+
+var fcolorType = reflect.TypeFor[cm.FColor]()
+
+// MarshalJSON encodes the theme with color strings
+func (t Theme) MarshalJSON() ([]byte, error) {
+	m := make(map[string]any)
+	rv := reflect.ValueOf(t)
+	for i := range rv.NumField() {
+		f := rv.Type().Field(i)
+		val := rv.Field(i).Interface()
+		if f.Type == fcolorType {
+			val = hexColor(val.(cm.FColor))
+		}
+		m[f.Name] = val
+	}
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON overwrites the theme fields present in data
+func (t *Theme) UnmarshalJSON(data []byte) error {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(t).Elem()
+	for name, raw := range m {
+		f := rv.FieldByName(name)
+		if !f.IsValid() {
+			return fmt.Errorf("ebitencm: unknown theme field %q", name)
+		}
+		dst := f.Addr().Interface()
+		if f.Type() == fcolorType {
+			dst = (*hexColor)(dst.(*cm.FColor))
+		}
+		if err := json.Unmarshal(raw, dst); err != nil {
+			return fmt.Errorf("ebitencm: theme field %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// LoadTheme reads a theme file. Missing fields fall back to DefaultTheme.
+//
+// Theme files are JSON objects keyed by the Theme field names.
+// Colors are "#rgb", "#rrggbb", "#rrggbbaa", a color name like "red",
+// or an {"R", "G", "B", "A"} object of floats [0-1].
+//
+//	{
+//		"DynamicBodyFill": "#3050ff",
+//		"StaticBodyStroke": "white",
+//		"Gradient": [{"Offset": 0, "Color": "black"}, {"Offset": 1, "Color": "red"}]
+//	}
+func LoadTheme(path string) (*Theme, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	t := DefaultTheme()
+	if err := json.Unmarshal(data, t); err != nil {
+		return nil, fmt.Errorf("ebitencm: %s: %w", path, err)
+	}
+	return t, nil
+}
+
+// SaveTheme writes the theme file
+func SaveTheme(path string, t *Theme) error {
+	data, err := json.MarshalIndent(t, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// LoadDrawingOptions reads a drawing options file. Missing fields fall back to DefaultDrawingOptions.
+//
+// Drawing options files are JSON objects keyed by the DrawingOptions field names.
+// Unknown fields are errors, as in theme files.
+func LoadDrawingOptions(path string) (*DrawingOptions, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	o := DefaultDrawingOptions()
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(o); err != nil {
+		return nil, fmt.Errorf("ebitencm: %s: %w", path, err)
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("ebitencm: %s: data after the options object", path)
+	}
+	return o, nil
+}
+
+// SaveDrawingOptions writes the drawing options file
+func SaveDrawingOptions(path string, o *DrawingOptions) error {
+	data, err := json.MarshalIndent(o, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// themeWatcher holds the theme reloaded by WatchTheme until the next DrawSpace
+type themeWatcher struct {
+	mu      sync.Mutex
+	pending *Theme
+}
+
+// WatchTheme polls the theme file every interval and loads it into the drawer when it changes.
+// A non-positive interval polls every second. The new theme is applied at the start of the next DrawSpace.
+// Load errors are logged.
+//
+// Call the returned function to stop watching.
+func (d *Drawer) WatchTheme(path string, interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	done := make(chan struct{})
+	go func() {
+		var modTime time.Time
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if info, err := os.Stat(path); err == nil && !info.ModTime().Equal(modTime) {
+				modTime = info.ModTime()
+				if t, err := LoadTheme(path); err != nil {
+					log.Println(err)
+				} else {
+					d.themeWatcher.mu.Lock()
+					d.themeWatcher.pending = t
+					d.themeWatcher.mu.Unlock()
+				}
+			}
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
+
+// applyWatchedTheme swaps in the theme reloaded by WatchTheme
+func (d *Drawer) applyWatchedTheme() {
+	d.themeWatcher.mu.Lock()
+	if d.themeWatcher.pending != nil {
+		d.Theme = d.themeWatcher.pending
+		d.themeWatcher.pending = nil
+	}
+	d.themeWatcher.mu.Unlock()
+}
@@ -0,0 +1,136 @@
+package ebitencm
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/setanarut/cm"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    cm.FColor
+		wantErr bool
+	}{
+		{in: "#fff", want: cm.FColor{R: 1, G: 1, B: 1, A: 1}},
+		{in: "#f00", want: cm.FColor{R: 1, G: 0, B: 0, A: 1}},
+		{in: "#3050ff", want: cm.FColor{R: 0x30 / 255.0, G: 0x50 / 255.0, B: 1, A: 1}},
+		{in: "#FF000080", want: cm.FColor{R: 1, G: 0, B: 0, A: 0x80 / 255.0}},
+		{in: "  Red ", want: cm.FColor{R: 1, G: 0, B: 0, A: 1}},
+		{in: "transparent", want: cm.FColor{}},
+		{in: "", wantErr: true},
+		{in: "ff0000", wantErr: true},
+		{in: "#12", wantErr: true},
+		{in: "#12345", wantErr: true},
+		{in: "#ggg", wantErr: true},
+		{in: "#+1234567", wantErr: true},
+		{in: "no-such-color", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseColor(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseColor(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if !tt.wantErr && !colorNear(got, tt.want) {
+				t.Errorf("ParseColor(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHexColor(t *testing.T) {
+	tests := []struct {
+		in   cm.FColor
+		want string
+	}{
+		{cm.FColor{R: 1, G: 0, B: 0, A: 1}, "#ff0000ff"},
+		{cm.FColor{R: 0.5, G: 0.5, B: 0.5, A: 0.5}, "#80808080"},
+		{cm.FColor{R: 2, G: -1, B: 0, A: 1}, "#ff0000ff"},
+	}
+	for _, tt := range tests {
+		if got := HexColor(tt.in); got != tt.want {
+			t.Errorf("HexColor(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+		if c, err := ParseColor(HexColor(tt.in)); err != nil || HexColor(c) != tt.want {
+			t.Errorf("ParseColor(HexColor(%v)) = %v, %v", tt.in, c, err)
+		}
+	}
+}
+
+// roundTripTheme encodes and decodes the theme
+func roundTripTheme(t *testing.T, theme *Theme) *Theme {
+	t.Helper()
+	data, err := json.Marshal(theme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := &Theme{}
+	if err := json.Unmarshal(data, got); err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+func TestThemeJSONRoundTrip(t *testing.T) {
+	for _, name := range ThemePresets() {
+		t.Run(name, func(t *testing.T) {
+			theme, err := ThemePreset(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			theme.Gradient = ViridisGradient()
+			// colors are quantized to 8 bits by the first round trip and exact after it
+			once := roundTripTheme(t, theme)
+			if twice := roundTripTheme(t, once); !reflect.DeepEqual(once, twice) {
+				t.Errorf("second round trip changed the theme\n%+v\n%+v", once, twice)
+			}
+			if !colorNear(once.DynamicBodyFill, theme.DynamicBodyFill) || !colorNear(once.GridMajor, theme.GridMajor) {
+				t.Errorf("colors changed: %v %v", once.DynamicBodyFill, theme.DynamicBodyFill)
+			}
+			if once.DynamicBodyColorMode != theme.DynamicBodyColorMode || once.ShadowOffset != theme.ShadowOffset ||
+				once.GlowBlur != theme.GlowBlur || once.GradientAutoRange != theme.GradientAutoRange {
+				t.Errorf("fields changed: %+v", once)
+			}
+			if len(once.Gradient) != len(theme.Gradient) || once.Gradient[1].Offset != theme.Gradient[1].Offset {
+				t.Errorf("gradient changed: %v", once.Gradient)
+			}
+		})
+	}
+}
+
+func TestThemeUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		check   func(*Theme) bool
+		wantErr bool
+	}{
+		{name: "partial keeps defaults", in: `{"DynamicBodyFill": "red"}`, check: func(th *Theme) bool {
+			return th.DynamicBodyFill == cm.FColor{R: 1, G: 0, B: 0, A: 1} && th.StaticBodyFill == DefaultTheme().StaticBodyFill
+		}},
+		{name: "color object", in: `{"Trail": {"R": 0.5, "G": 0.25, "B": 0, "A": 1}}`, check: func(th *Theme) bool {
+			return th.Trail == cm.FColor{R: 0.5, G: 0.25, B: 0, A: 1}
+		}},
+		{name: "gradient", in: `{"Gradient": [{"Offset": 0, "Color": "black"}, {"Offset": 1, "Color": "#fff"}]}`, check: func(th *Theme) bool {
+			return len(th.Gradient) == 2 && th.Gradient[1].Color == cm.FColor{R: 1, G: 1, B: 1, A: 1}
+		}},
+		{name: "unknown field", in: `{"DynamicBodyFil": "red"}`, wantErr: true},
+		{name: "bad color", in: `{"DynamicBodyFill": "#12"}`, wantErr: true},
+		{name: "not an object", in: `[]`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			th := DefaultTheme()
+			err := json.Unmarshal([]byte(tt.in), th)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !tt.check(th) {
+				t.Errorf("unexpected theme %+v", th)
+			}
+		})
+	}
+}
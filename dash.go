@@ -0,0 +1,188 @@
+package ebitencm
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/setanarut/v"
+)
+
+// Dash is a stroke dash pattern. The zero value is a solid line.
+type Dash struct {
+	// Pattern alternates dash and gap lengths in world units.
+	// Zero length dashes are drawn as round dots.
+	Pattern []float64
+	// Phase offsets the start of the pattern
+	Phase float64
+}
+
+// DashedLine returns a dash pattern of dash long lines separated by gap
+func DashedLine(dash, gap float64) Dash {
+	return Dash{Pattern: []float64{dash, gap}}
+}
+
+// DottedLine returns a dash pattern of round dots separated by gap
+func DottedLine(gap float64) Dash {
+	return Dash{Pattern: []float64{0, gap}}
+}
+
+// IsSolid reports whether the dash pattern draws a solid line
+func (d Dash) IsSolid() bool {
+	for _, l := range d.Pattern {
+		if l > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// hasDots reports whether the pattern contains zero length dashes
+func (d Dash) hasDots() bool {
+	for i := 0; i < len(d.Pattern); i += 2 {
+		if d.Pattern[i] == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// dotLength is the length of the tiny line emitted for zero-length dashes, so line caps can draw a dot
+const dotLength = 0.01
+
+// pathBuilder is the part of vector.Path used by dashPath
+type pathBuilder interface {
+	MoveTo(x, y float32)
+	LineTo(x, y float32)
+}
+
+var _ pathBuilder = (*vector.Path)(nil)
+
+// dashPath appends the polyline to path as dashes.
+// A solid dash appends a solid polyline.
+func dashPath(path pathBuilder, pts []v.Vec, closed bool, dash Dash) {
+	pattern := dash.Pattern
+	if len(pts) < 2 {
+		return
+	}
+	if closed {
+		pts = append(pts[:len(pts):len(pts)], pts[0])
+	}
+
+	total := 0.0
+	for _, l := range pattern {
+		total += l
+	}
+	if total <= 0 {
+		path.MoveTo(float32(pts[0].X), float32(pts[0].Y))
+		for _, p := range pts[1:] {
+			path.LineTo(float32(p.X), float32(p.Y))
+		}
+		return
+	}
+
+	idx := 0
+	left := math.Mod(dash.Phase, total)
+	if left < 0 {
+		left += total
+	}
+	for left > 0 && left >= pattern[idx] {
+		left -= pattern[idx]
+		idx = (idx + 1) % len(pattern)
+	}
+	remain := pattern[idx] - left
+	on := idx%2 == 0
+
+	startDash := func(p, dir v.Vec) {
+		path.MoveTo(float32(p.X), float32(p.Y))
+		if remain == 0 {
+			q := p.Add(dir.Scale(dotLength))
+			path.LineTo(float32(q.X), float32(q.Y))
+		}
+	}
+
+	started := false
+	for i := 1; i < len(pts); i++ {
+		a, b := pts[i-1], pts[i]
+		segLen := a.Dist(b)
+		if segLen == 0 {
+			continue
+		}
+		dir := b.Sub(a).Scale(1 / segLen)
+		if !started {
+			if on {
+				startDash(a, dir)
+			}
+			started = true
+		}
+		t := 0.0
+		for segLen-t > remain {
+			t += remain
+			p := a.Add(dir.Scale(t))
+			if on {
+				path.LineTo(float32(p.X), float32(p.Y))
+			}
+			idx = (idx + 1) % len(pattern)
+			remain = pattern[idx]
+			on = idx%2 == 0
+			if on {
+				startDash(p, dir)
+			}
+		}
+		remain -= segLen - t
+		if on {
+			path.LineTo(float32(b.X), float32(b.Y))
+		}
+	}
+}
+
+// arcPoints appends the points of an arc from angle a0 to a1 to pts
+func arcPoints(pts []v.Vec, center v.Vec, radius, a0, a1 float64) []v.Vec {
+	n := int(math.Ceil(math.Abs(a1-a0) * math.Max(radius, 1) / 4))
+	n = min(max(n, 4), 128)
+	for i := 0; i <= n; i++ {
+		a := a0 + (a1-a0)*float64(i)/float64(n)
+		pts = append(pts, v.Vec{X: center.X + math.Cos(a)*radius, Y: center.Y + math.Sin(a)*radius})
+	}
+	return pts
+}
+
+// circlePoints returns the closed outline of a circle
+func circlePoints(center v.Vec, radius float64) []v.Vec {
+	pts := arcPoints(nil, center, radius, 0, 2*math.Pi)
+	return pts[:len(pts)-1]
+}
+
+// capsulePoints returns the closed outline of a fat segment
+func capsulePoints(a, b v.Vec, radius float64) []v.Vec {
+	t1 := math.Atan2(b.Y-a.Y, b.X-a.X) + math.Pi/2
+	t2 := t1 + math.Pi
+	pts := arcPoints(nil, a, radius, t1, t1+math.Pi)
+	return arcPoints(pts, b, radius, t2, t2+math.Pi)
+}
+
+// polygonPoints returns the closed outline of a polygon with rounded corners
+func polygonPoints(verts []v.Vec, radius float64) []v.Vec {
+	if radius == 0 {
+		return verts
+	}
+	count := len(verts)
+	pts := make([]v.Vec, 0, count*8)
+	for i := range count {
+		v0 := verts[(i-1+count)%count]
+		v1 := verts[i]
+		v2 := verts[(i+1)%count]
+		n1 := reversePerp(v1.Sub(v0)).Unit()
+		n2 := reversePerp(v2.Sub(v1)).Unit()
+		a0 := math.Atan2(n1.Y, n1.X)
+		a1 := math.Atan2(n2.Y, n2.X)
+		// turn the short way around the corner
+		for a1-a0 > math.Pi {
+			a1 -= 2 * math.Pi
+		}
+		for a1-a0 < -math.Pi {
+			a1 += 2 * math.Pi
+		}
+		pts = arcPoints(pts, v1, radius, a0, a1)
+	}
+	return pts
+}
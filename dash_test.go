@@ -0,0 +1,98 @@
+package ebitencm
+
+import (
+	"math"
+	"testing"
+
+	"github.com/setanarut/v"
+)
+
+// pathRecorder records the subpaths appended by dashPath
+type pathRecorder struct {
+	subpaths [][]v.Vec
+}
+
+func (r *pathRecorder) MoveTo(x, y float32) {
+	r.subpaths = append(r.subpaths, []v.Vec{{X: float64(x), Y: float64(y)}})
+}
+
+func (r *pathRecorder) LineTo(x, y float32) {
+	last := &r.subpaths[len(r.subpaths)-1]
+	*last = append(*last, v.Vec{X: float64(x), Y: float64(y)})
+}
+
+func TestDashPath(t *testing.T) {
+	line := []v.Vec{{X: 0, Y: 0}, {X: 6, Y: 0}}
+	square := []v.Vec{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 1}}
+	corner := []v.Vec{{X: 0, Y: 0}, {X: 2, Y: 0}, {X: 2, Y: 2}}
+	tests := []struct {
+		name   string
+		pts    []v.Vec
+		closed bool
+		dash   Dash
+		// want are the first and last points of each dash
+		want [][2]v.Vec
+	}{
+		{"solid", line, false, Dash{}, [][2]v.Vec{{{X: 0}, {X: 6}}}},
+		{"zero pattern", line, false, Dash{Pattern: []float64{0, 0}}, [][2]v.Vec{{{X: 0}, {X: 6}}}},
+		{"dashed", line, false, DashedLine(2, 1), [][2]v.Vec{{{X: 0}, {X: 2}}, {{X: 3}, {X: 5}}}},
+		{"phase inside dash", line, false, Dash{Pattern: []float64{2, 1}, Phase: 1}, [][2]v.Vec{{{X: 0}, {X: 1}}, {{X: 2}, {X: 4}}, {{X: 5}, {X: 6}}}},
+		{"phase at gap", line, false, Dash{Pattern: []float64{2, 1}, Phase: 2}, [][2]v.Vec{{{X: 1}, {X: 3}}, {{X: 4}, {X: 6}}}},
+		{"negative phase", line, false, Dash{Pattern: []float64{2, 1}, Phase: -1}, [][2]v.Vec{{{X: 1}, {X: 3}}, {{X: 4}, {X: 6}}}},
+		{"phase over period", line, false, Dash{Pattern: []float64{2, 1}, Phase: 5}, [][2]v.Vec{{{X: 1}, {X: 3}}, {{X: 4}, {X: 6}}}},
+		{"around corner", corner, false, DashedLine(3, 1), [][2]v.Vec{{{X: 0}, {X: 2, Y: 1}}}},
+		{"closed", square, true, DashedLine(1, 1), [][2]v.Vec{{{X: 0}, {X: 1}}, {{X: 1, Y: 1}, {X: 0, Y: 1}}}},
+		{"single point", line[:1], false, DashedLine(1, 1), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r pathRecorder
+			dashPath(&r, tt.pts, tt.closed, tt.dash)
+			if len(r.subpaths) != len(tt.want) {
+				t.Fatalf("got %d dashes %v, want %d", len(r.subpaths), r.subpaths, len(tt.want))
+			}
+			for i, sp := range r.subpaths {
+				first, last := sp[0], sp[len(sp)-1]
+				if first.Dist(tt.want[i][0]) > 1e-6 || last.Dist(tt.want[i][1]) > 1e-6 {
+					t.Errorf("dash %d from %v to %v, want %v to %v", i, first, last, tt.want[i][0], tt.want[i][1])
+				}
+			}
+		})
+	}
+}
+
+func TestDashPathDots(t *testing.T) {
+	tests := []struct {
+		name string
+		dash Dash
+		want []float64 // x of each dot
+	}{
+		{"dotted", DottedLine(2), []float64{0, 2}},
+		{"dotted phase", Dash{Pattern: []float64{0, 2}, Phase: 1}, []float64{1, 3}},
+		{"dash dot", Dash{Pattern: []float64{1, 1, 0, 1}}, []float64{2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r pathRecorder
+			dashPath(&r, []v.Vec{{X: 0, Y: 0}, {X: 4, Y: 0}}, false, tt.dash)
+			var dots []float64
+			for _, sp := range r.subpaths {
+				lo, hi := math.Inf(1), math.Inf(-1)
+				for _, p := range sp {
+					lo, hi = min(lo, p.X), max(hi, p.X)
+				}
+				if hi-lo <= dotLength*1.5 {
+					dots = append(dots, lo)
+				}
+			}
+			if len(dots) != len(tt.want) {
+				t.Fatalf("got dots at %v, want %v", dots, tt.want)
+			}
+			for i := range dots {
+				if math.Abs(dots[i]-tt.want[i]) > 1e-6 {
+					t.Errorf("dot %d at %v, want %v", i, dots[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
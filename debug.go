@@ -0,0 +1,200 @@
+package ebitencm
+
+import (
+	"image"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/setanarut/cm"
+	"github.com/setanarut/v"
+)
+
+// debug font glyph size of ebitenutil.DebugPrint
+const debugGlyphW, debugGlyphH = 6, 16
+
+type debugKind int
+
+const (
+	debugLine debugKind = iota
+	debugArrow
+	debugCircle
+	debugPolygon
+	debugText
+)
+
+// debugItem is a queued debug primitive in world coordinates
+type debugItem struct {
+	kind    debugKind
+	points  []v.Vec
+	radius  float64
+	text    string
+	clr     cm.FColor
+	expires time.Time
+	// gen is the queue generation of zero lifetime items, -1 for timed items
+	gen int
+}
+
+// debugTickPeriod returns the duration of an Update at the current TPS
+func debugTickPeriod() time.Duration {
+	tps := ebiten.TPS()
+	if tps <= 0 {
+		tps = ebiten.DefaultTPS
+	}
+	return time.Second / time.Duration(tps)
+}
+
+// queueDebug adds the item to the debug queue. Zero lifetime items are drawn until the next
+// queue after a DrawSpace replaces them, or for two Update periods. So items queued at every Update
+// are drawn in every frame even when Draw is called more often than Update, and items queued once
+// disappear after a frame.
+func (d *Drawer) queueDebug(it debugItem, lifetime time.Duration) {
+	now := time.Now()
+	if d.debugDrawn {
+		d.debugGen++
+		d.debugDrawn = false
+		d.pruneDebug(now)
+	} else if now.Sub(d.debugPruned) >= debugTickPeriod() {
+		// prune without DrawSpace when LayerDebug is not drawn
+		d.pruneDebug(now)
+	}
+	it.gen = -1
+	if lifetime <= 0 {
+		it.gen = d.debugGen
+		it.expires = now.Add(2 * debugTickPeriod())
+	} else {
+		it.expires = now.Add(lifetime)
+	}
+	d.debugQueue = append(d.debugQueue, it)
+}
+
+// pruneDebug removes the expired items and the zero lifetime items of previous generations
+func (d *Drawer) pruneDebug(now time.Time) {
+	d.debugPruned = now
+	n := 0
+	for _, it := range d.debugQueue {
+		if now.Before(it.expires) && (it.gen < 0 || it.gen == d.debugGen) {
+			d.debugQueue[n] = it
+			n++
+		}
+	}
+	clear(d.debugQueue[n:])
+	d.debugQueue = d.debugQueue[:n]
+}
+
+// DebugLine queues a line from a to b
+func (d *Drawer) DebugLine(a, b v.Vec, clr cm.FColor, lifetime time.Duration) {
+	d.queueDebug(debugItem{kind: debugLine, points: []v.Vec{a, b}, clr: clr}, lifetime)
+}
+
+// DebugArrow queues an arrow from a to b
+func (d *Drawer) DebugArrow(a, b v.Vec, clr cm.FColor, lifetime time.Duration) {
+	d.queueDebug(debugItem{kind: debugArrow, points: []v.Vec{a, b}, clr: clr}, lifetime)
+}
+
+// DebugCircle queues a circle outline
+func (d *Drawer) DebugCircle(center v.Vec, radius float64, clr cm.FColor, lifetime time.Duration) {
+	d.queueDebug(debugItem{kind: debugCircle, points: []v.Vec{center}, radius: radius, clr: clr}, lifetime)
+}
+
+// DebugBox queues a box outline
+func (d *Drawer) DebugBox(bb cm.BB, clr cm.FColor, lifetime time.Duration) {
+	pts := []v.Vec{{X: bb.L, Y: bb.B}, {X: bb.R, Y: bb.B}, {X: bb.R, Y: bb.T}, {X: bb.L, Y: bb.T}}
+	d.queueDebug(debugItem{kind: debugPolygon, points: pts, clr: clr}, lifetime)
+}
+
+// DebugPolygon queues a closed polygon outline. The vertices are copied.
+func (d *Drawer) DebugPolygon(verts []v.Vec, clr cm.FColor, lifetime time.Duration) {
+	d.queueDebug(debugItem{kind: debugPolygon, points: append([]v.Vec(nil), verts...), clr: clr}, lifetime)
+}
+
+// DebugText queues text with the top left corner at the world position. The text is not scaled by the camera.
+func (d *Drawer) DebugText(pos v.Vec, text string, clr cm.FColor, lifetime time.Duration) {
+	d.queueDebug(debugItem{kind: debugText, points: []v.Vec{pos}, text: text, clr: clr}, lifetime)
+}
+
+// ClearDebug removes all queued debug primitives
+func (d *Drawer) ClearDebug() {
+	clear(d.debugQueue)
+	d.debugQueue = d.debugQueue[:0]
+}
+
+// drawDebug removes the expired items and draws the debug queue
+func (d *Drawer) drawDebug() {
+	d.debugDrawn = true
+	d.pruneDebug(time.Now())
+	w := d.DrawingOptions.DebugStrokeWidth
+	for _, it := range d.debugQueue {
+		switch it.kind {
+		case debugLine:
+			d.drawSegment(it.points[0], it.points[1], it.clr, w)
+		case debugArrow:
+			d.drawArrow(it.points[0], it.points[1], it.clr, w)
+		case debugCircle:
+			d.drawRing(it.points[0], it.radius, it.clr, w)
+		case debugPolygon:
+			d.drawPolyline(it.points, true, it.clr, w)
+		case debugText:
+			d.drawDebugText(it.points[0], it.text, it.clr)
+		}
+	}
+}
+
+// drawPolyline strokes the connected points
+func (d *Drawer) drawPolyline(pts []v.Vec, closed bool, clr cm.FColor, strokeWidth float32) {
+	if len(pts) < 2 {
+		return
+	}
+	path := vector.Path{}
+	path.MoveTo(float32(pts[0].X), float32(pts[0].Y))
+	for _, p := range pts[1:] {
+		path.LineTo(float32(p.X), float32(p.Y))
+	}
+	if closed {
+		path.Close()
+	}
+	if !d.strokeDash.IsSolid() {
+		path = vector.Path{}
+		dashPath(&path, pts, closed, d.strokeDash)
+	}
+	d.strokePath(d.Screen, path, clr.R, clr.G, clr.B, clr.A, strokeWidth)
+}
+
+// drawDebugText draws the text tinted with the color at the screen position of pos
+func (d *Drawer) drawDebugText(pos v.Vec, text string, clr cm.FColor) {
+	x, y := d.GeoM.Apply(pos.X, pos.Y)
+	d.drawScreenText(x, y, text, clr)
+}
+
+// drawScreenText draws the text tinted with the color with the top left corner at the screen position
+func (d *Drawer) drawScreenText(x, y float64, text string, clr cm.FColor) {
+	lines := strings.Split(text, "\n")
+	cols := 0
+	for _, l := range lines {
+		cols = max(cols, len([]rune(l)))
+	}
+	w, h := cols*debugGlyphW, len(lines)*debugGlyphH
+	if w == 0 {
+		return
+	}
+	if d.textImage == nil || d.textImage.Bounds().Dx() < w || d.textImage.Bounds().Dy() < h {
+		iw, ih := w, h
+		if d.textImage != nil {
+			iw, ih = max(iw, d.textImage.Bounds().Dx()), max(ih, d.textImage.Bounds().Dy())
+			d.textImage.Deallocate()
+		}
+		d.textImage = ebiten.NewImage(iw, ih)
+	}
+	d.textImage.Clear()
+	ebitenutil.DebugPrintAt(d.textImage, text, 0, 0)
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(x, y)
+	r, g, b, a := d.layerColor(clr.R, clr.G, clr.B, clr.A)
+	op.ColorScale.Scale(r*a, g*a, b*a, a)
+	submit := d.profStart()
+	d.Screen.DrawImage(d.textImage.SubImage(image.Rect(0, 0, w, h)).(*ebiten.Image), op)
+	d.countDraw(2, submit)
+}
@@ -0,0 +1,85 @@
+package ebitencm
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/setanarut/cm"
+	"github.com/setanarut/v"
+)
+
+// DrawBody draws the shapes of the body with the theme colors and styles
+func (d *Drawer) DrawBody(body *cm.Body, screen *ebiten.Image) {
+	d.Screen = screen
+	body.EachShape(d.queueThemedShape)
+	d.flushShapes()
+}
+
+// DrawShape draws the shape with the theme colors and style
+func (d *Drawer) DrawShape(shape *cm.Shape, screen *ebiten.Image) {
+	d.Screen = screen
+	d.queueThemedShape(shape)
+	d.flushShapes()
+}
+
+// DrawConstraint draws the constraint with the theme colors and DrawingOptions.ConstraintDash
+func (d *Drawer) DrawConstraint(constraint *cm.Constraint, screen *ebiten.Image) {
+	d.Screen = screen
+	d.strokeDash = d.DrawingOptions.ConstraintDash
+	d.drawConstraint(constraint, d.DrawingOptions.ConstraintsStrokeWidth)
+	d.strokeDash = Dash{}
+}
+
+// DrawArbiter draws the contact normals of the arbiter
+func (d *Drawer) DrawArbiter(arb *cm.Arbiter, screen *ebiten.Image) {
+	d.Screen = screen
+	d.drawArbiter(arb)
+}
+
+// DrawCircle draws a circle with a radius line at the angle
+func (d *Drawer) DrawCircle(center v.Vec, angle, radius float64, outline, fill cm.FColor, strokeWidth float32, screen *ebiten.Image) {
+	d.Screen = screen
+	d.drawCircle(center, angle, radius, outline, fill, strokeWidth)
+}
+
+// DrawCapsule draws a segment with rounded ends of the radius
+func (d *Drawer) DrawCapsule(a, b v.Vec, radius float64, outline, fill cm.FColor, strokeWidth float32, screen *ebiten.Image) {
+	d.Screen = screen
+	d.drawFatSegment(a, b, radius, outline, fill, strokeWidth)
+}
+
+// DrawPolygon draws a convex polygon with corners rounded by the radius
+func (d *Drawer) DrawPolygon(verts []v.Vec, radius float64, outline, fill cm.FColor, strokeWidth float32, screen *ebiten.Image) {
+	d.Screen = screen
+	d.drawPolygon(len(verts), verts, radius, outline, fill, strokeWidth)
+}
+
+// DrawLine draws a line from a to b
+func (d *Drawer) DrawLine(a, b v.Vec, clr cm.FColor, strokeWidth float32, screen *ebiten.Image) {
+	d.Screen = screen
+	d.drawSegment(a, b, clr, strokeWidth)
+}
+
+// DrawArrow draws a line from a to b with an arrowhead at b
+func (d *Drawer) DrawArrow(a, b v.Vec, clr cm.FColor, strokeWidth float32, screen *ebiten.Image) {
+	d.Screen = screen
+	d.drawArrow(a, b, clr, strokeWidth)
+}
+
+// DrawDot draws a filled circle
+func (d *Drawer) DrawDot(pos v.Vec, radius float64, clr cm.FColor, screen *ebiten.Image) {
+	d.Screen = screen
+	d.drawDot(radius, pos, clr)
+}
+
+// drawArrow draws a line with an arrowhead of 4 stroke widths, at most half of the line
+func (d *Drawer) drawArrow(a, b v.Vec, clr cm.FColor, strokeWidth float32) {
+	d.drawSegment(a, b, clr, strokeWidth)
+	delta := b.Sub(a)
+	length := delta.Mag()
+	if length == 0 {
+		return
+	}
+	head := min(float64(max(strokeWidth, 1))*4, length/2)
+	back := delta.Scale(-head / length)
+	d.drawSegment(b, b.Add(back.Rotate(0.5)), clr, strokeWidth)
+	d.drawSegment(b, b.Add(back.Rotate(-0.5)), clr, strokeWidth)
+}
@@ -3,6 +3,7 @@ package ebitencm
 import (
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/setanarut/cm"
@@ -123,54 +124,184 @@ func (drw *Drawer) drawConstraint(constraint *cm.Constraint, strokeWidth float32
 
 }
 
-// DrawSpace draws all shapes in space with the drawer implementation
-func (drw *Drawer) DrawSpace(space *cm.Space, screen *ebiten.Image) {
-	drw.Screen = screen
+// drawBodies draws the static and dynamic shapes of the space
+func (drw *Drawer) drawBodies(space *cm.Space) {
+	drw.drawStaticBodies(space)
+	drw.drawDynamicBodies(space)
+}
 
-	if !drw.DrawingOptions.StaticBodyDisabled {
-		space.EachStaticShape(func(shape *cm.Shape) {
-			drw.drawShape(shape, drw.Theme.StaticBodyStroke, drw.Theme.StaticBodyFill, drw.DrawingOptions.StaticBodyStrokeWidth)
-		})
+// drawStaticBodies draws the static shapes of the space
+func (drw *Drawer) drawStaticBodies(space *cm.Space) {
+	if drw.DrawingOptions.StaticBodyDisabled {
+		return
 	}
+	drw.layer = LayerStatic
+	space.EachStaticShape(drw.queueFilteredShape)
+	drw.flushShapes()
+}
 
-	if !drw.DrawingOptions.DynamicBodyDisabled {
-		space.EachDynamicShape(func(shape *cm.Shape) {
+// drawDynamicBodies draws the dynamic shapes of the space
+func (drw *Drawer) drawDynamicBodies(space *cm.Space) {
+	if drw.DrawingOptions.DynamicBodyDisabled {
+		return
+	}
+	drw.layer = LayerDynamic
+	drw.updateGradientRange(space)
+	space.EachDynamicShape(drw.queueFilteredShape)
+	drw.flushShapes()
+}
 
-			var clr cm.FColor
+// shapeVisible reports whether the shape passes DrawingOptions.CategoryMask and Drawer.ShapeFilter.
+// A zero CategoryMask shows all categories.
+func (drw *Drawer) shapeVisible(shape *cm.Shape) bool {
+	if mask := drw.DrawingOptions.CategoryMask; mask != 0 && shape.Filter.Categories&mask == 0 {
+		return false
+	}
+	return drw.ShapeFilter == nil || drw.ShapeFilter(shape)
+}
 
-			if shape.Body.IsSleeping() {
-				clr = drw.Theme.DynamicBodySleepingFill
-			} else if shape.Body.IdleTime() > shape.Space.SleepTimeThreshold {
-				clr = drw.Theme.DynamicBodyIdleFill
-			} else {
-				clr = drw.Theme.DynamicBodyFill
-			}
+// queueFilteredShape queues the shape if it is visible
+func (drw *Drawer) queueFilteredShape(shape *cm.Shape) {
+	if drw.shapeVisible(shape) {
+		drw.queueThemedShape(shape)
+	}
+}
 
-			drw.drawShape(shape, drw.Theme.DynamicBodyStroke, clr, drw.DrawingOptions.DynamicBodyStrokeWidth)
-		})
+// queueThemedShape queues the shape with the theme colors of its body
+func (drw *Drawer) queueThemedShape(shape *cm.Shape) {
+	if shape.Body.Type() == cm.Static {
+		drw.queueShape(shape, drw.Theme.StaticBodyStroke, drw.Theme.StaticBodyFill, drw.DrawingOptions.StaticBodyStrokeWidth)
+		return
 	}
 
-	if !drw.DrawingOptions.ConstraintDisabled {
-		space.EachConstraint(func(c *cm.Constraint) {
+	var clr cm.FColor
+
+	if shape.Body.IsSleeping() {
+		clr = drw.Theme.DynamicBodySleepingFill
+	} else if shape.Space != nil && shape.Body.IdleTime() > shape.Space.SleepTimeThreshold {
+		clr = drw.Theme.DynamicBodyIdleFill
+	} else {
+		clr = drw.dynamicBodyFill(shape.Body)
+	}
+
+	drw.queueShape(shape, drw.Theme.DynamicBodyStroke, clr, drw.DrawingOptions.DynamicBodyStrokeWidth)
+}
+
+// drawConstraints draws the constraints of the space
+func (drw *Drawer) drawConstraints(space *cm.Space) {
+	if drw.DrawingOptions.ConstraintDisabled {
+		return
+	}
+	drw.layer = LayerConstraints
+	drw.strokeDash = drw.DrawingOptions.ConstraintDash
+	space.EachConstraint(func(c *cm.Constraint) {
+		if drw.ConstraintFilter == nil || drw.ConstraintFilter(c) {
 			drw.drawConstraint(c, drw.DrawingOptions.ConstraintsStrokeWidth)
-		})
+		}
+	})
+	drw.strokeDash = Dash{}
+}
 
+// drawCollisions draws the contact normals of the space arbiters
+func (drw *Drawer) drawCollisions(space *cm.Space) {
+	if drw.DrawingOptions.CollisionNormalDisabled {
+		return
 	}
-	// Draw Collision Point
-	if !drw.DrawingOptions.CollisionNormalDisabled {
-		for _, arb := range space.Arbiters {
-
-			bodyA, bodyB := arb.Bodies()
-
-			n := arb.Normal()
-			for j := 0; j < arb.Count(); j++ {
-				p1 := bodyA.Position().Add(arb.Contacts[j].R1)
-				p2 := bodyB.Position().Add(arb.Contacts[j].R2)
-				a := p1.Add(n.Scale(-drw.DrawingOptions.CollisionNormalLength / 2))
-				b := p2.Add(n.Scale(drw.DrawingOptions.CollisionNormalLength / 2))
-				drw.drawSegment(a, b, drw.Theme.CollisionNormal, drw.DrawingOptions.CollisionNormalStrokeWidth)
-			}
+	drw.layer = LayerCollisions
+	for _, arb := range space.Arbiters {
+		if a, b := arb.Shapes(); drw.shapeVisible(a) && drw.shapeVisible(b) {
+			drw.drawArbiter(arb)
 		}
+	}
+}
+
+// drawArbiter draws the contact normals of the arbiter
+func (drw *Drawer) drawArbiter(arb *cm.Arbiter) {
+	bodyA, bodyB := arb.Bodies()
+
+	n := arb.Normal()
+	for j := 0; j < arb.Count(); j++ {
+		p1 := bodyA.Position().Add(arb.Contacts[j].R1)
+		p2 := bodyB.Position().Add(arb.Contacts[j].R2)
+		a := p1.Add(n.Scale(-drw.DrawingOptions.CollisionNormalLength / 2))
+		b := p2.Add(n.Scale(drw.DrawingOptions.CollisionNormalLength / 2))
+		drw.drawSegment(a, b, drw.Theme.CollisionNormal, drw.DrawingOptions.CollisionNormalStrokeWidth)
+	}
+}
+
+// DrawSpace draws all shapes in space with the drawer implementation.
+// The layers are drawn in Drawer.LayerOrder.
+func (drw *Drawer) DrawSpace(space *cm.Space, screen *ebiten.Image) {
+	start := time.Now()
+	drw.Screen = screen
+	drw.applyWatchedTheme()
+	drw.updateThemeTween()
 
+	for _, layer := range drw.layerOrder() {
+		drw.drawLayer(layer, space)
+	}
+	drw.layer = layerNone
+	drw.drawTime = time.Since(start)
+	drw.lastStats, drw.stats = drw.stats, drawStats{}
+	if drw.Profiler != nil {
+		drw.Profiler.endFrame(drw.drawTime)
+	}
+}
+
+// drawLayer draws a built-in or custom layer
+func (drw *Drawer) drawLayer(layer Layer, space *cm.Space) {
+	switch layer {
+	case LayerSprites:
+		if !drw.DrawingOptions.SpritesDisabled {
+			drw.layer = LayerSprites
+			drw.drawSprites(space)
+		}
+	case LayerGrid:
+		if drw.DrawingOptions.GridEnabled {
+			drw.layer = LayerGrid
+			drw.drawGrid()
+		}
+	case LayerGraphs:
+		if drw.DrawingOptions.GraphsEnabled {
+			drw.layer = LayerGraphs
+			drw.drawGraphs()
+		}
+	case LayerProfiler:
+		if drw.Profiler != nil && drw.Profiler.Overlay {
+			drw.layer = LayerProfiler
+			drw.drawProfiler()
+		}
+	case LayerHUD:
+		if drw.DrawingOptions.HUDEnabled {
+			drw.layer = LayerHUD
+			drw.drawHUD(space)
+		}
+	case LayerEffects:
+		drw.layer = LayerEffects
+		drw.drawEffects(space)
+	case LayerTrails:
+		if !drw.DrawingOptions.TrailsDisabled {
+			drw.layer = LayerTrails
+			drw.drawTrails(space)
+		}
+	case LayerStatic:
+		drw.drawStaticBodies(space)
+	case LayerDynamic:
+		drw.drawDynamicBodies(space)
+	case LayerConstraints:
+		drw.drawConstraints(space)
+	case LayerCollisions:
+		drw.drawCollisions(space)
+	case LayerQueries:
+		drw.layer = LayerQueries
+		drw.drawQueries()
+	case LayerDebug:
+		drw.layer = LayerDebug
+		drw.drawDebug()
+	default:
+		if fn := drw.customLayers[layer]; fn != nil {
+			drw.layer = layer
+			fn(space, drw.Screen)
+		}
 	}
 }
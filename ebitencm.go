@@ -3,6 +3,7 @@ package ebitencm
 import (
 	"image/color"
 	"math"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
@@ -22,9 +23,55 @@ type Drawer struct {
 
 	DrawTriangleStrokeOpt *ebiten.DrawTrianglesOptions
 	DrawTriagleFillOpt    *ebiten.DrawTrianglesOptions
+
+	// LayerOrder is the draw order of the layers in DrawSpace. Layers not listed are not drawn.
+	LayerOrder []Layer
+
+	// Queries recorded by the QueryRecorder are drawn in LayerQueries
+	QueryRecorder *QueryRecorder
+	// Profiler times Step, HandleMouseEvent and DrawSpace when set
+	Profiler *Profiler
+
+	// ShapeFilter reports whether DrawSpace draws the shape. nil draws all shapes.
+	ShapeFilter func(shape *cm.Shape) bool
+	// ConstraintFilter reports whether DrawSpace draws the constraint. nil draws all constraints.
+	ConstraintFilter func(constraint *cm.Constraint) bool
 	// private
-	handler    mouseEventHandler
-	whiteImage *ebiten.Image
+	handler      mouseEventHandler
+	whiteImage   *ebiten.Image
+	bodyStyles   map[*cm.Body]*Style
+	shapeStyles  map[*cm.Shape]*Style
+	bodySprites  map[*cm.Body]*Sprite
+	bodyTrails   map[*cm.Body]*Trail
+	shapeQueue   []shapeItem
+	strokeDash   Dash
+	fillTexture  *Texture
+	currentBody  *cm.Body
+	fillMat      *Material
+	strokeMat    *Material
+	uniforms     map[string]any
+	sdf          bool
+	effectImage  *ebiten.Image
+	blurImage    *ebiten.Image
+	layers       map[Layer]*LayerOptions
+	layer        Layer
+	order        []Layer
+	customLayers map[Layer]LayerFunc
+	debugQueue   []debugItem
+	debugGen     int
+	debugDrawn   bool
+	debugPruned  time.Time
+	textImage    *ebiten.Image
+	stats        drawStats
+	lastStats    drawStats
+	stepTime     time.Duration
+	drawTime     time.Duration
+	graphs       [graphMetricCount]*Graph
+
+	themeWatcher themeWatcher
+	themeTween   *ThemeTween
+
+	gradientMin, gradientMax float64
 }
 
 func NewDrawer() *Drawer {
@@ -37,6 +84,8 @@ func NewDrawer() *Drawer {
 		whiteImage:            whiteImage,
 		GeoM:                  &ebiten.GeoM{},
 		Theme:                 DefaultTheme(),
+		LayerOrder:            DefaultLayerOrder(),
+		layer:                 layerNone,
 	}
 }
 
@@ -57,6 +106,11 @@ func (d *Drawer) drawCircle(
 	outline, fill cm.FColor,
 	strokeWidth float32,
 ) {
+	if d.useSDF(radius) {
+		outline, fill = d.sdfColors(outline, fill)
+		d.drawSDFCapsule(pos, pos, angle, radius, true, outline, fill, strokeWidth)
+		return
+	}
 	// angle *= flipFactor
 	path := &vector.Path{}
 	path.Arc(
@@ -82,6 +136,12 @@ func (d *Drawer) drawCircle(
 	path.Close()
 	// Stroke
 	if !d.DrawingOptions.AllStrokesDisabled {
+		if !d.strokeDash.IsSolid() {
+			*path = vector.Path{}
+			edge := v.Vec{X: pos.X + math.Cos(angle)*radius, Y: pos.Y + math.Sin(angle)*radius}
+			dashPath(path, circlePoints(pos, radius), true, d.strokeDash)
+			dashPath(path, []v.Vec{pos, edge}, false, d.strokeDash)
+		}
 		d.strokePath(d.Screen, *path, outline.R, outline.G, outline.B, outline.A, strokeWidth)
 	}
 }
@@ -96,6 +156,10 @@ func (d *Drawer) drawSegment(a, b v.Vec, clr cm.FColor, strokeWidth float32) {
 		d.fillPath(d.Screen, path, clr.R, clr.G, clr.B, clr.A)
 	}
 	if !d.DrawingOptions.AllStrokesDisabled {
+		if !d.strokeDash.IsSolid() {
+			path = vector.Path{}
+			dashPath(&path, []v.Vec{a, b}, false, d.strokeDash)
+		}
 		d.strokePath(d.Screen, path, clr.R, clr.G, clr.B, clr.A, strokeWidth)
 	}
 }
@@ -106,6 +170,11 @@ func (d *Drawer) drawFatSegment(
 	outline, fillColor cm.FColor,
 	strokeWidth float32,
 ) {
+	if d.useSDF(radius) {
+		outline, fillColor = d.sdfColors(outline, fillColor)
+		d.drawSDFCapsule(a, b, 0, radius, false, outline, fillColor, strokeWidth)
+		return
+	}
 
 	var path vector.Path = vector.Path{}
 	t1 := float32(math.Atan2(b.Y-a.Y, b.X-a.X)) + math.Pi/2
@@ -133,6 +202,10 @@ func (d *Drawer) drawFatSegment(
 	}
 
 	if !d.DrawingOptions.AllStrokesDisabled {
+		if !d.strokeDash.IsSolid() {
+			path = vector.Path{}
+			dashPath(&path, capsulePoints(a, b, radius), true, d.strokeDash)
+		}
 		d.strokePath(d.Screen, path, outline.R, outline.G, outline.B, outline.A, strokeWidth)
 	}
 }
@@ -199,12 +272,20 @@ func (d *Drawer) drawPolygon(count int, verts []v.Vec, radius float64, outline,
 		d.fillPath(d.Screen, *path, fill.R, fill.G, fill.B, fill.A)
 	}
 	if !d.DrawingOptions.AllStrokesDisabled {
+		if !d.strokeDash.IsSolid() {
+			*path = vector.Path{}
+			dashPath(path, polygonPoints(verts[:count], radius), true, d.strokeDash)
+		}
 		d.strokePath(d.Screen, *path, outline.R, outline.G, outline.B, outline.A, strokeWidth)
 	}
 }
 
 func (d *Drawer) drawDot(radius float64, pos v.Vec, fill cm.FColor) {
 	if !d.DrawingOptions.AllDotsDisabled {
+		if d.useSDF(radius) {
+			d.drawSDFCapsule(pos, pos, 0, radius, false, cm.FColor{}, fill, 0)
+			return
+		}
 		var path *vector.Path = &vector.Path{}
 		path.Arc(
 			float32(pos.X),
@@ -220,33 +301,91 @@ func (d *Drawer) drawDot(radius float64, pos v.Vec, fill cm.FColor) {
 }
 
 func (d *Drawer) HandleMouseEvent(space *cm.Space) {
+	start := d.profStart()
 	d.handler.handleMouseEvent(d, space)
+	d.profAdd(profileMouse, start)
 }
 
-func (d *Drawer) strokePath(screen *ebiten.Image, path vector.Path, r, g, b, a float32, w float32) {
+// strokeOptions returns the stroke options of DrawingOptions with the width
+func (d *Drawer) strokeOptions(w float32) *vector.StrokeOptions {
 	sop := &vector.StrokeOptions{}
 	sop.Width = w
-	sop.LineJoin = vector.LineJoinRound
+	sop.LineCap = d.DrawingOptions.LineCap
+	sop.LineJoin = d.DrawingOptions.LineJoin
+	sop.MiterLimit = d.DrawingOptions.MiterLimit
+	if d.strokeDash.hasDots() {
+		sop.LineCap = vector.LineCapRound
+	}
+	return sop
+}
+
+func (d *Drawer) strokePath(screen *ebiten.Image, path vector.Path, r, g, b, a float32, w float32) {
+	sop := d.strokeOptions(w)
+	tess := d.profStart()
 	vs, is := path.AppendVerticesAndIndicesForStroke(nil, nil, sop)
+	r, g, b, a = d.layerColor(r, g, b, a)
+	op := d.layerTriangleOptions(d.DrawTriangleStrokeOpt)
+	if m := d.strokeMat; m != nil && m.Shader != nil {
+		d.profAdd(profileTessellation, tess)
+		d.drawTrianglesMaterial(screen, vs, is, m, r, g, b, a, op)
+		return
+	}
 	applyMatrixToVertices(vs, d.GeoM, r, g, b, a)
-	screen.DrawTriangles(vs, is, d.whiteImage, d.DrawTriangleStrokeOpt)
+	d.profAdd(profileTessellation, tess)
+	submit := d.profStart()
+	screen.DrawTriangles(vs, is, d.whiteImage, op)
+	d.countDraw(len(is)/3, submit)
 }
 
 func (d *Drawer) fillPath(screen *ebiten.Image, path vector.Path, r, g, b, a float32) {
+	tess := d.profStart()
 	vs, is := path.AppendVerticesAndIndicesForFilling(nil, nil)
+	r, g, b, a = d.layerColor(r, g, b, a)
+	op := d.layerTriangleOptions(d.DrawTriagleFillOpt)
+	if m := d.fillMat; m != nil && m.Shader != nil {
+		d.profAdd(profileTessellation, tess)
+		d.drawTrianglesMaterial(screen, vs, is, m, r, g, b, a, op)
+		return
+	}
+	if tex := d.fillTexture; tex != nil && tex.Image != nil {
+		tex.mapVertices(vs, d.currentBody)
+		transformVertices(vs, d.GeoM, r, g, b, a)
+		texOp := *op
+		texOp.Address = ebiten.AddressRepeat
+		d.profAdd(profileTessellation, tess)
+		submit := d.profStart()
+		screen.DrawTriangles(vs, is, tex.Image, &texOp)
+		d.countDraw(len(is)/3, submit)
+		return
+	}
 	applyMatrixToVertices(vs, d.GeoM, r, g, b, a)
-	screen.DrawTriangles(vs, is, d.whiteImage, d.DrawTriagleFillOpt)
+	d.profAdd(profileTessellation, tess)
+	submit := d.profStart()
+	screen.DrawTriangles(vs, is, d.whiteImage, op)
+	d.countDraw(len(is)/3, submit)
 }
 
 func applyMatrixToVertices(vs []ebiten.Vertex, matrix *ebiten.GeoM, r, g, b, a float32) {
+	transformVertices(vs, matrix, r, g, b, a)
+	for i := range vs {
+		vs[i].SrcX, vs[i].SrcY = 1, 1
+	}
+}
+
+// transformVertices applies the matrix and the color to the vertices, keeping the source coordinates
+func transformVertices(vs []ebiten.Vertex, matrix *ebiten.GeoM, r, g, b, a float32) {
 	for i := range vs {
 		x, y := matrix.Apply(float64(vs[i].DstX), float64(vs[i].DstY))
 		vs[i].DstX, vs[i].DstY = float32(x), float32(y)
-		vs[i].SrcX, vs[i].SrcY = 1, 1
 		vs[i].ColorR, vs[i].ColorG, vs[i].ColorB, vs[i].ColorA = r, g, b, a
 	}
 }
 
+// vec returns the vertex position as a vector
+func vec(x, y float32) v.Vec {
+	return v.Vec{X: float64(x), Y: float64(y)}
+}
+
 // ScreenToWorld converts screen-space coordinates to world-space
 func ScreenToWorld(screenPoint v.Vec, cameraGeoM ebiten.GeoM) v.Vec {
 	if cameraGeoM.IsInvertible() {
@@ -282,8 +421,45 @@ type Theme struct {
 	DynamicBodyIdleFill           cm.FColor
 	DynamicBodySleepingFill       cm.FColor
 	DynamicBodyStroke             cm.FColor
+	GraphBackground               cm.FColor
+	GraphLine                     cm.FColor
+	GraphText                     cm.FColor
+	GridAxisX                     cm.FColor
+	GridAxisY                     cm.FColor
+	GridLabel                     cm.FColor
+	GridMajor                     cm.FColor
+	GridMinor                     cm.FColor
+	HUDBackground                 cm.FColor
+	HUDText                       cm.FColor
+	QueryHit                      cm.FColor
+	QueryStroke                   cm.FColor
+	SensorFill                    cm.FColor
+	SensorStroke                  cm.FColor
 	StaticBodyFill                cm.FColor
 	StaticBodyStroke              cm.FColor
+	Trail                         cm.FColor
+	Trajectory                    cm.FColor
+
+	// DynamicBodyColorMode selects the fill color of awake dynamic bodies.
+	// Sleeping and idle bodies always use DynamicBodySleepingFill and DynamicBodyIdleFill.
+	DynamicBodyColorMode ColorMode
+	// Gradient sampled by the body property color modes
+	Gradient Gradient
+	// Property range mapped to Gradient. Ignored when GradientAutoRange is set
+	GradientMin, GradientMax float64
+	// GradientAutoRange normalizes the property over all awake dynamic bodies every frame
+	GradientAutoRange bool
+
+	// Drop shadow drawn under the shapes when DrawingOptions.ShadowEnabled is set
+	ShadowColor cm.FColor
+	// Shadow offset in screen pixels
+	ShadowOffset v.Vec
+	// Shadow blur radius in screen pixels
+	ShadowBlur float64
+	// Glow drawn under the shapes when DrawingOptions.GlowEnabled is set
+	GlowColor cm.FColor
+	// Glow blur radius in screen pixels
+	GlowBlur float64
 }
 
 // SetOpacity overwrites all Theme color alphas [0-1}]
@@ -302,8 +478,26 @@ func (d *Drawer) SetOpacity(alpha float32) {
 	d.Theme.DynamicBodyIdleFill.A = alpha
 	d.Theme.DynamicBodySleepingFill.A = alpha
 	d.Theme.DynamicBodyStroke.A = alpha
+	d.Theme.GraphBackground.A = alpha
+	d.Theme.GraphLine.A = alpha
+	d.Theme.GraphText.A = alpha
+	d.Theme.GridAxisX.A = alpha
+	d.Theme.GridAxisY.A = alpha
+	d.Theme.GridLabel.A = alpha
+	d.Theme.GridMajor.A = alpha
+	d.Theme.GridMinor.A = alpha
+	d.Theme.HUDBackground.A = alpha
+	d.Theme.HUDText.A = alpha
+	d.Theme.QueryHit.A = alpha
+	d.Theme.QueryStroke.A = alpha
+	d.Theme.SensorFill.A = alpha
+	d.Theme.SensorStroke.A = alpha
 	d.Theme.StaticBodyFill.A = alpha
 	d.Theme.StaticBodyStroke.A = alpha
+	d.Theme.Trail.A = alpha
+	d.Theme.Trajectory.A = alpha
+	d.Theme.ShadowColor.A = alpha
+	d.Theme.GlowColor.A = alpha
 }
 
 func DefaultTheme() *Theme {
@@ -322,8 +516,32 @@ func DefaultTheme() *Theme {
 		DynamicBodyIdleFill:           cm.FColor{0.5, 0.5, 0.5, 1},
 		DynamicBodySleepingFill:       cm.FColor{0.5, 0.5, 0.5, 1},
 		DynamicBodyStroke:             cm.FColor{0.69, 0.165, 0.537, 1},
+		GraphBackground:               cm.FColor{0, 0, 0, 0.6},
+		GraphLine:                     cm.FColor{0.3, 0.9, 1, 1},
+		GraphText:                     cm.FColor{1, 1, 1, 1},
+		GridAxisX:                     cm.FColor{1, 0.3, 0.3, 0.8},
+		GridAxisY:                     cm.FColor{0.3, 1, 0.3, 0.8},
+		GridLabel:                     cm.FColor{1, 1, 1, 0.7},
+		GridMajor:                     cm.FColor{1, 1, 1, 0.25},
+		GridMinor:                     cm.FColor{1, 1, 1, 0.08},
+		HUDBackground:                 cm.FColor{0, 0, 0, 0.6},
+		HUDText:                       cm.FColor{1, 1, 1, 1},
+		QueryHit:                      cm.FColor{1, 0.3, 0.2, 1},
+		QueryStroke:                   cm.FColor{1, 1, 1, 0.6},
+		SensorFill:                    cm.FColor{0.2, 0.8, 0.4, 0.25},
+		SensorStroke:                  cm.FColor{0.2, 0.8, 0.4, 1},
 		StaticBodyFill:                cm.FColor{0.6, 0.3, 0.5, 1},
 		StaticBodyStroke:              cm.FColor{0.69, 0.165, 0.537, 1},
+		Trail:                         cm.FColor{1, 0.8, 0.3, 1},
+		Trajectory:                    cm.FColor{1, 1, 1, 0.8},
+		DynamicBodyColorMode:          ColorModeTheme,
+		Gradient:                      ViridisGradient(),
+		GradientAutoRange:             true,
+		ShadowColor:                   cm.FColor{0, 0, 0, 0.5},
+		ShadowOffset:                  v.Vec{X: 4, Y: 6},
+		ShadowBlur:                    6,
+		GlowColor:                     cm.FColor{0.4, 0.6, 1, 0.8},
+		GlowBlur:                      10,
 	}
 }
 
@@ -331,16 +549,44 @@ type DrawingOptions struct {
 	AllDotsDisabled            bool
 	AllFillsDisabled           bool
 	AllStrokesDisabled         bool
+	CategoryMask               uint
 	CollisionNormalDisabled    bool
 	CollisionNormalLength      float64
 	CollisionNormalStrokeWidth float32
+	ConstraintDash             Dash
 	ConstraintDisabled         bool
+	ConstraintsUnderBodies     bool
+	DebugStrokeWidth           float32
 	ConstraintsDotRadius       float64
 	ConstraintsStrokeWidth     float32
 	DynamicBodyDisabled        bool
 	DynamicBodyStrokeWidth     float32
+	GlowEnabled                bool
+	GraphPosition              v.Vec
+	GraphsEnabled              bool
+	GraphSize                  v.Vec
+	GraphStrokeWidth           float32
+	GridEnabled                bool
+	GridLabels                 bool
+	GridMinSpacing             float64
+	GridStrokeWidth            float32
+	HUDEnabled                 bool
+	HUDPosition                v.Vec
+	LineCap                    vector.LineCap
+	LineJoin                   vector.LineJoin
+	MiterLimit                 float32
+	QueryStrokeWidth           float32
+	SensorDash                 Dash
+	SensorDisabled             bool
+	SensorOccupiedOnly         bool
+	SensorStrokeWidth          float32
+	ShadowEnabled              bool
+	SpritesDisabled            bool
 	StaticBodyDisabled         bool
 	StaticBodyStrokeWidth      float32
+	TrailsDisabled             bool
+	TrailStrokeWidth           float32
+	TrajectoryStrokeWidth      float32
 }
 
 func DefaultDrawingOptions() *DrawingOptions {
@@ -348,16 +594,44 @@ func DefaultDrawingOptions() *DrawingOptions {
 		AllDotsDisabled:            false,
 		AllFillsDisabled:           false,
 		AllStrokesDisabled:         false,
+		CategoryMask:               cm.AllCategories,
 		CollisionNormalDisabled:    false,
 		CollisionNormalLength:      12,
 		CollisionNormalStrokeWidth: 2,
+		ConstraintDash:             Dash{},
 		ConstraintDisabled:         false,
+		ConstraintsUnderBodies:     false,
+		DebugStrokeWidth:           1,
 		ConstraintsDotRadius:       2,
 		ConstraintsStrokeWidth:     2,
 		DynamicBodyDisabled:        false,
 		DynamicBodyStrokeWidth:     2,
+		GlowEnabled:                false,
+		GraphPosition:              v.Vec{X: 8, Y: 120},
+		GraphsEnabled:              false,
+		GraphSize:                  v.Vec{X: 200, Y: 48},
+		GraphStrokeWidth:           1,
+		GridEnabled:                false,
+		GridLabels:                 false,
+		GridMinSpacing:             16,
+		GridStrokeWidth:            1,
+		HUDEnabled:                 false,
+		HUDPosition:                v.Vec{X: 8, Y: 8},
+		LineCap:                    vector.LineCapButt,
+		LineJoin:                   vector.LineJoinRound,
+		MiterLimit:                 10,
+		QueryStrokeWidth:           1,
+		SensorDash:                 DashedLine(6, 4),
+		SensorDisabled:             false,
+		SensorOccupiedOnly:         false,
+		SensorStrokeWidth:          2,
+		ShadowEnabled:              false,
+		SpritesDisabled:            false,
 		StaticBodyDisabled:         false,
 		StaticBodyStrokeWidth:      2,
+		TrailsDisabled:             false,
+		TrailStrokeWidth:           2,
+		TrajectoryStrokeWidth:      3,
 	}
 }
 
@@ -0,0 +1,120 @@
+package ebitencm
+
+import (
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/setanarut/cm"
+)
+
+// blurShaderSrc is a one dimensional gaussian blur.
+// Colorize replaces the color of the blurred image with Tint (premultiplied).
+var blurShaderSrc = []byte(`//kage:unit pixels
+
+package main
+
+var Direction vec2
+var Radius float
+var Tint vec4
+var Colorize float
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	sigma := max(Radius/2, 0.5)
+	sum := vec4(0)
+	wsum := 0.0
+	for i := -16; i <= 16; i++ {
+		x := float(i) * Radius / 16
+		w := exp(-x * x / (2 * sigma * sigma))
+		sum += imageSrc0At(srcPos+Direction*x) * w
+		wsum += w
+	}
+	sum /= wsum
+	if Colorize > 0 {
+		return Tint * sum.a
+	}
+	return sum
+}
+`)
+
+var blurShader *ebiten.Shader
+
+// drawEffects draws the enabled shadow and glow passes of the static and dynamic shapes
+// whose layers are in the layer order
+func (d *Drawer) drawEffects(space *cm.Space) {
+	shadow, glow := d.DrawingOptions.ShadowEnabled, d.DrawingOptions.GlowEnabled
+	if !shadow && !glow {
+		return
+	}
+	if blurShader == nil {
+		s, err := ebiten.NewShader(blurShaderSrc)
+		if err != nil {
+			panic(err)
+		}
+		blurShader = s
+	}
+
+	screen := d.Screen
+	bounds := screen.Bounds()
+	if d.effectImage == nil || d.effectImage.Bounds().Size() != bounds.Size() {
+		if d.effectImage != nil {
+			d.effectImage.Deallocate()
+			d.blurImage.Deallocate()
+		}
+		d.effectImage = ebiten.NewImage(bounds.Dx(), bounds.Dy())
+		d.blurImage = ebiten.NewImage(bounds.Dx(), bounds.Dy())
+	}
+
+	// render the silhouettes offscreen, the screen may be a sub-image
+	d.effectImage.Clear()
+	d.Screen = d.effectImage
+	geoM := *d.GeoM
+	d.GeoM.Translate(-float64(bounds.Min.X), -float64(bounds.Min.Y))
+	order := d.layerOrder()
+	if slices.Contains(order, LayerStatic) {
+		d.drawStaticBodies(space)
+	}
+	if slices.Contains(order, LayerDynamic) {
+		d.drawDynamicBodies(space)
+	}
+	*d.GeoM = geoM
+	d.Screen = screen
+	d.layer = LayerEffects
+
+	if shadow {
+		t := d.Theme
+		d.blurComposite(t.ShadowColor, t.ShadowBlur, t.ShadowOffset.X, t.ShadowOffset.Y, ebiten.BlendSourceOver)
+	}
+	if glow {
+		d.blurComposite(d.Theme.GlowColor, d.Theme.GlowBlur, 0, 0, ebiten.BlendLighter)
+	}
+}
+
+// blurComposite blurs the effect image, tints it and draws it to the screen at the offset
+func (d *Drawer) blurComposite(clr cm.FColor, radius, offsetX, offsetY float64, blend ebiten.Blend) {
+	w, h := d.effectImage.Bounds().Dx(), d.effectImage.Bounds().Dy()
+
+	d.blurImage.Clear()
+	hop := &ebiten.DrawRectShaderOptions{}
+	hop.Images[0] = d.effectImage
+	hop.Uniforms = map[string]any{
+		"Direction": []float32{1, 0},
+		"Radius":    float32(radius),
+	}
+	submit := d.profStart()
+	d.blurImage.DrawRectShader(w, h, blurShader, hop)
+	d.countDraw(2, submit)
+
+	vop := &ebiten.DrawRectShaderOptions{Blend: blend}
+	vop.Images[0] = d.blurImage
+	origin := d.Screen.Bounds().Min
+	vop.GeoM.Translate(offsetX+float64(origin.X), offsetY+float64(origin.Y))
+	vop.Uniforms = map[string]any{
+		"Direction": []float32{0, 1},
+		"Radius":    float32(radius),
+		"Tint":      []float32{clr.R * clr.A, clr.G * clr.A, clr.B * clr.A, clr.A},
+		"Colorize":  float32(1),
+	}
+	submit = d.profStart()
+	d.Screen.DrawRectShader(w, h, blurShader, vop)
+	d.countDraw(2, submit)
+}
@@ -26,7 +26,8 @@ type Game struct {
 func (g *Game) Update() error {
 	// Handling dragging
 	g.drawer.HandleMouseEvent(g.space)
-	g.space.Step(1 / 60.0)
+	// Step and measure the step time for the HUD
+	g.drawer.Step(g.space, 1/60.0)
 	return nil
 }
 
@@ -64,6 +65,8 @@ func main() {
 	game := &Game{}
 	game.space = space
 	game.drawer = ebitencm.NewDrawer()
+	game.drawer.DrawingOptions.HUDEnabled = true
+	game.drawer.DrawingOptions.HUDPosition = v.Vec{8, 20}
 	// game.drawer.StrokeDisabled = true
 	// game.drawer.FillDisabled = true
 	ebiten.SetWindowSize(int(screenSize.X), int(screenSize.Y))
@@ -0,0 +1,60 @@
+package ebitencm
+
+import (
+	"math"
+
+	"github.com/setanarut/cm"
+)
+
+// GradientStop is a color at an offset [0-1] of a Gradient
+type GradientStop struct {
+	Offset float64
+	Color  cm.FColor
+}
+
+// Gradient is a list of color stops sorted by offset
+type Gradient []GradientStop
+
+// At returns the gradient color at t [0-1]
+func (g Gradient) At(t float64) cm.FColor {
+	if len(g) == 0 {
+		return cm.FColor{}
+	}
+	if math.IsNaN(t) || t <= g[0].Offset {
+		return g[0].Color
+	}
+	for i := 1; i < len(g); i++ {
+		if t <= g[i].Offset {
+			a, b := g[i-1], g[i]
+			f := float32((t - a.Offset) / (b.Offset - a.Offset))
+			return cm.FColor{
+				R: a.Color.R + (b.Color.R-a.Color.R)*f,
+				G: a.Color.G + (b.Color.G-a.Color.G)*f,
+				B: a.Color.B + (b.Color.B-a.Color.B)*f,
+				A: a.Color.A + (b.Color.A-a.Color.A)*f,
+			}
+		}
+	}
+	return g[len(g)-1].Color
+}
+
+// ViridisGradient returns the perceptually uniform viridis color map
+func ViridisGradient() Gradient {
+	return Gradient{
+		{0.00, cm.FColor{R: 0.267, G: 0.005, B: 0.329, A: 1}},
+		{0.25, cm.FColor{R: 0.229, G: 0.322, B: 0.546, A: 1}},
+		{0.50, cm.FColor{R: 0.128, G: 0.567, B: 0.551, A: 1}},
+		{0.75, cm.FColor{R: 0.369, G: 0.789, B: 0.383, A: 1}},
+		{1.00, cm.FColor{R: 0.993, G: 0.906, B: 0.144, A: 1}},
+	}
+}
+
+// HeatGradient returns a black-red-yellow-white heat color map
+func HeatGradient() Gradient {
+	return Gradient{
+		{0.00, cm.FColor{R: 0, G: 0, B: 0, A: 1}},
+		{0.35, cm.FColor{R: 1, G: 0, B: 0, A: 1}},
+		{0.70, cm.FColor{R: 1, G: 1, B: 0, A: 1}},
+		{1.00, cm.FColor{R: 1, G: 1, B: 1, A: 1}},
+	}
+}
@@ -0,0 +1,51 @@
+package ebitencm
+
+import (
+	"math"
+	"testing"
+
+	"github.com/setanarut/cm"
+)
+
+func TestGradientAt(t *testing.T) {
+	black := cm.FColor{R: 0, G: 0, B: 0, A: 1}
+	red := cm.FColor{R: 1, G: 0, B: 0, A: 1}
+	blue := cm.FColor{R: 0, G: 0, B: 1, A: 1}
+	white := cm.FColor{R: 1, G: 1, B: 1, A: 1}
+	g := Gradient{{0, black}, {0.5, red}, {1, white}}
+	hard := Gradient{{0, black}, {0.5, red}, {0.5, blue}, {1, white}}
+	tests := []struct {
+		name string
+		g    Gradient
+		t    float64
+		want cm.FColor
+	}{
+		{"empty", nil, 0.5, cm.FColor{}},
+		{"single stop", Gradient{{0.3, red}}, 0.9, red},
+		{"below first", g, -1, black},
+		{"first stop", g, 0, black},
+		{"between", g, 0.25, cm.FColor{R: 0.5, G: 0, B: 0, A: 1}},
+		{"middle stop", g, 0.5, red},
+		{"after middle", g, 0.75, cm.FColor{R: 1, G: 0.5, B: 0.5, A: 1}},
+		{"last stop", g, 1, white},
+		{"above last", g, 2, white},
+		{"NaN", g, math.NaN(), black},
+		{"hard stop", hard, 0.5, red},
+		{"after hard stop", hard, 0.75, cm.FColor{R: 0.5, G: 0.5, B: 1, A: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.g.At(tt.t)
+			if !colorNear(got, tt.want) {
+				t.Errorf("At(%v) = %v, want %v", tt.t, got, tt.want)
+			}
+		})
+	}
+}
+
+// colorNear reports whether the colors are equal within half a 8-bit step
+func colorNear(a, b cm.FColor) bool {
+	const eps = 0.5/255 + 1e-6
+	return math.Abs(float64(a.R-b.R)) <= eps && math.Abs(float64(a.G-b.G)) <= eps &&
+		math.Abs(float64(a.B-b.B)) <= eps && math.Abs(float64(a.A-b.A)) <= eps
+}
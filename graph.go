@@ -0,0 +1,200 @@
+package ebitencm
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/setanarut/cm"
+	"github.com/setanarut/v"
+)
+
+// GraphMetric is a space value plotted by the drawer graphs
+type GraphMetric int
+
+const (
+	// GraphKineticEnergy is the sum of Body.KineticEnergy of the dynamic bodies
+	GraphKineticEnergy GraphMetric = iota
+	// GraphLinearMomentum is the magnitude of the total linear momentum
+	GraphLinearMomentum
+	// GraphAngularMomentum is the total angular momentum around the world origin
+	GraphAngularMomentum
+	// GraphContacts is the number of contact points
+	GraphContacts
+	// GraphStepTime is the duration of Drawer.Step in milliseconds
+	GraphStepTime
+	graphMetricCount
+)
+
+var graphLabels = [graphMetricCount]string{"Kinetic energy", "Linear momentum", "Angular momentum", "Contacts", "Step ms"}
+
+// Graph is a time series of values drawn as a line plot
+type Graph struct {
+	Label string
+	// Samples is the number of values kept. SampleGraphs called at every Update records 60 values per second at the default TPS.
+	Samples int
+	// Min and Max are the vertical range of the plot, widened by 1 when equal. Ignored with AutoScale.
+	Min, Max float64
+	// AutoScale fits the vertical range to the kept values
+	AutoScale bool
+	// Color of the line. nil uses Theme.GraphLine
+	Color *cm.FColor
+	// Hidden graphs are sampled but not drawn
+	Hidden bool
+
+	values []float64
+	head   int
+	count  int
+}
+
+// NewGraph returns an auto scaled graph keeping the number of samples
+func NewGraph(label string, samples int) *Graph {
+	return &Graph{Label: label, Samples: samples, AutoScale: true}
+}
+
+// Add appends the value, dropping the oldest when full
+func (g *Graph) Add(value float64) {
+	if g.Samples <= 0 {
+		return
+	}
+	if len(g.values) != g.Samples {
+		g.values = make([]float64, g.Samples)
+		g.head, g.count = 0, 0
+	}
+	g.values[g.head] = value
+	g.head = (g.head + 1) % len(g.values)
+	g.count = min(g.count+1, len(g.values))
+}
+
+// Last returns the newest value or 0
+func (g *Graph) Last() float64 {
+	if g.count == 0 {
+		return 0
+	}
+	return g.at(g.count - 1)
+}
+
+// Clear removes all values
+func (g *Graph) Clear() {
+	g.head, g.count = 0, 0
+}
+
+// at returns the i-th value from the oldest
+func (g *Graph) at(i int) float64 {
+	n := len(g.values)
+	return g.values[(g.head-g.count+i+n)%n]
+}
+
+// yRange returns the vertical range of the plot
+func (g *Graph) yRange() (lo, hi float64) {
+	if !g.AutoScale {
+		if g.Min == g.Max {
+			return g.Min - 1, g.Max + 1
+		}
+		return g.Min, g.Max
+	}
+	lo, hi = math.Inf(1), math.Inf(-1)
+	for i := range g.count {
+		lo, hi = min(lo, g.at(i)), max(hi, g.at(i))
+	}
+	if lo == hi {
+		lo, hi = lo-1, hi+1
+	}
+	return lo, hi
+}
+
+// Graph returns the graph of the metric for configuration. The graph keeps 300 samples by default.
+func (d *Drawer) Graph(metric GraphMetric) *Graph {
+	if d.graphs[metric] == nil {
+		d.graphs[metric] = NewGraph(graphLabels[metric], 300)
+	}
+	return d.graphs[metric]
+}
+
+// SampleGraphs records the metrics of the space. Call it from Update after stepping the space.
+func (d *Drawer) SampleGraphs(space *cm.Space) {
+	var energy, angular float64
+	var linear v.Vec
+	space.EachDynamicBody(func(body *cm.Body) {
+		if body.Type() != cm.Dynamic {
+			return
+		}
+		energy += body.KineticEnergy()
+		p := body.Velocity().Scale(body.Mass())
+		linear = linear.Add(p)
+		angular += body.Position().Cross(p) + body.Moment()*body.AngularVelocity()
+	})
+	contacts := 0
+	for _, arb := range space.Arbiters {
+		contacts += arb.Count()
+	}
+	d.Graph(GraphKineticEnergy).Add(energy)
+	d.Graph(GraphLinearMomentum).Add(linear.Mag())
+	d.Graph(GraphAngularMomentum).Add(angular)
+	d.Graph(GraphContacts).Add(float64(contacts))
+	d.Graph(GraphStepTime).Add(float64(d.stepTime.Microseconds()) / 1000)
+}
+
+// drawGraphs draws the visible graphs stacked down from DrawingOptions.GraphPosition in screen coordinates
+func (d *Drawer) drawGraphs() {
+	pos, size := d.DrawingOptions.GraphPosition, d.DrawingOptions.GraphSize
+	for _, g := range d.graphs {
+		if g == nil || g.Hidden {
+			continue
+		}
+		d.drawGraph(g, pos, size)
+		pos.Y += size.Y + 4
+	}
+}
+
+// drawGraph draws the background, the line and the label with the newest value of the graph.
+// The line of a graph whose Samples changed since the last value is not drawn until a value is added.
+func (d *Drawer) drawGraph(g *Graph, pos, size v.Vec) {
+	t := d.Theme
+	d.fillScreenRect(pos, size, t.GraphBackground)
+	if g.count > 1 && len(g.values) == g.Samples {
+		lo, hi := g.yRange()
+		clr := t.GraphLine
+		if g.Color != nil {
+			clr = *g.Color
+		}
+		path := vector.Path{}
+		for i := range g.count {
+			x := pos.X + size.X*float64(i)/float64(g.Samples-1)
+			y := pos.Y + size.Y*(1-(min(max(g.at(i), lo), hi)-lo)/(hi-lo))
+			if i == 0 {
+				path.MoveTo(float32(x), float32(y))
+			} else {
+				path.LineTo(float32(x), float32(y))
+			}
+		}
+		d.strokeScreenPath(path, clr, d.DrawingOptions.GraphStrokeWidth)
+	}
+	d.drawScreenText(pos.X+2, pos.Y, fmt.Sprintf("%s %.4g", g.Label, g.Last()), t.GraphText)
+}
+
+// fillScreenRect fills a rectangle in screen coordinates, without Drawer.GeoM
+func (d *Drawer) fillScreenRect(pos, size v.Vec, clr cm.FColor) {
+	x0, y0, x1, y1 := float32(pos.X), float32(pos.Y), float32(pos.X+size.X), float32(pos.Y+size.Y)
+	vs := []ebiten.Vertex{{DstX: x0, DstY: y0}, {DstX: x1, DstY: y0}, {DstX: x1, DstY: y1}, {DstX: x0, DstY: y1}}
+	is := []uint16{0, 1, 2, 0, 2, 3}
+	r, g, b, a := d.layerColor(clr.R, clr.G, clr.B, clr.A)
+	applyMatrixToVertices(vs, &ebiten.GeoM{}, r, g, b, a)
+	submit := d.profStart()
+	d.Screen.DrawTriangles(vs, is, d.whiteImage, d.layerTriangleOptions(d.DrawTriagleFillOpt))
+	d.countDraw(2, submit)
+}
+
+// strokeScreenPath strokes the path in screen coordinates, without Drawer.GeoM
+func (d *Drawer) strokeScreenPath(path vector.Path, clr cm.FColor, w float32) {
+	tess := d.profStart()
+	sop := d.strokeOptions(w)
+	vs, is := path.AppendVerticesAndIndicesForStroke(nil, nil, sop)
+	r, g, b, a := d.layerColor(clr.R, clr.G, clr.B, clr.A)
+	applyMatrixToVertices(vs, &ebiten.GeoM{}, r, g, b, a)
+	d.profAdd(profileTessellation, tess)
+	submit := d.profStart()
+	d.Screen.DrawTriangles(vs, is, d.whiteImage, d.layerTriangleOptions(d.DrawTriangleStrokeOpt))
+	d.countDraw(len(is)/3, submit)
+}
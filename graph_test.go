@@ -0,0 +1,50 @@
+package ebitencm
+
+import (
+	"slices"
+	"testing"
+)
+
+// graphStep sets the number of samples of the graph and adds the value
+type graphStep struct {
+	samples int
+	value   float64
+}
+
+func TestGraphAdd(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []graphStep
+		// want are the kept values from the oldest
+		want []float64
+		last float64
+	}{
+		{"empty", nil, nil, 0},
+		{"partial", []graphStep{{3, 1}, {3, 2}}, []float64{1, 2}, 2},
+		{"full", []graphStep{{3, 1}, {3, 2}, {3, 3}}, []float64{1, 2, 3}, 3},
+		{"wrapped", []graphStep{{3, 1}, {3, 2}, {3, 3}, {3, 4}, {3, 5}}, []float64{3, 4, 5}, 5},
+		{"grow", []graphStep{{2, 1}, {2, 2}, {2, 3}, {4, 4}, {4, 5}}, []float64{4, 5}, 5},
+		{"shrink", []graphStep{{4, 1}, {4, 2}, {4, 3}, {2, 4}, {2, 5}, {2, 6}}, []float64{5, 6}, 6},
+		{"zero samples", []graphStep{{3, 1}, {3, 2}, {0, 3}}, []float64{1, 2}, 2},
+		{"negative samples", []graphStep{{-1, 1}}, nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGraph(tt.name, 0)
+			for _, s := range tt.steps {
+				g.Samples = s.samples
+				g.Add(s.value)
+			}
+			var got []float64
+			for i := range g.count {
+				got = append(got, g.at(i))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("values %v, want %v", got, tt.want)
+			}
+			if g.Last() != tt.last {
+				t.Errorf("Last() = %v, want %v", g.Last(), tt.last)
+			}
+		})
+	}
+}
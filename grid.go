@@ -0,0 +1,129 @@
+package ebitencm
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/setanarut/cm"
+)
+
+// maxGridLines is the most grid lines drawn per axis
+const maxGridLines = 1000
+
+// gridSpacing returns the smallest 1, 2 or 5 times a power of ten world spacing
+// that is at least minPixels apart on the screen. minPixels is at least 1.
+func gridSpacing(pixelsPerUnit, minPixels float64) float64 {
+	if !(minPixels >= 1) {
+		minPixels = 1
+	}
+	target := minPixels / pixelsPerUnit
+	base := math.Pow(10, math.Floor(math.Log10(target)))
+	for _, m := range []float64{1, 2, 5, 10} {
+		if base*m >= target {
+			return base * m
+		}
+	}
+	return base * 10
+}
+
+// visibleWorldBB returns the world bounds of the screen under Drawer.GeoM
+func (d *Drawer) visibleWorldBB() (bb cm.BB, ok bool) {
+	inv := *d.GeoM
+	if !inv.IsInvertible() {
+		return bb, false
+	}
+	inv.Invert()
+	b := d.Screen.Bounds()
+	bb = cm.BB{L: math.Inf(1), B: math.Inf(1), R: math.Inf(-1), T: math.Inf(-1)}
+	for _, c := range [][2]float64{{0, 0}, {float64(b.Dx()), 0}, {0, float64(b.Dy())}, {float64(b.Dx()), float64(b.Dy())}} {
+		x, y := inv.Apply(c[0], c[1])
+		bb.L, bb.R = min(bb.L, x), max(bb.R, x)
+		bb.B, bb.T = min(bb.B, y), max(bb.T, y)
+	}
+	return bb, true
+}
+
+// drawGrid draws the minor and major grid lines covering the screen, the origin axes and the labels
+func (d *Drawer) drawGrid() {
+	bb, ok := d.visibleWorldBB()
+	if !ok {
+		return
+	}
+	g := d.GeoM
+	scale := math.Sqrt(math.Abs(g.Element(0, 0)*g.Element(1, 1) - g.Element(0, 1)*g.Element(1, 0)))
+	minor := gridSpacing(scale, d.DrawingOptions.GridMinSpacing)
+	if !(minor > 0) || math.IsInf(minor, 0) {
+		return
+	}
+	major := minor * 5
+	w := d.DrawingOptions.GridStrokeWidth / float32(scale)
+
+	x0, x1 := math.Ceil(bb.L/minor), math.Floor(bb.R/minor)
+	y0, y1 := math.Ceil(bb.B/minor), math.Floor(bb.T/minor)
+	if !(x1-x0 <= maxGridLines && y1-y0 <= maxGridLines) {
+		return
+	}
+	var minorPath, majorPath vector.Path
+	for n := range int(x1-x0) + 1 {
+		i := x0 + float64(n)
+		x := float32(i * minor)
+		p := &minorPath
+		if math.Mod(i, 5) == 0 {
+			p = &majorPath
+		}
+		p.MoveTo(x, float32(bb.B))
+		p.LineTo(x, float32(bb.T))
+	}
+	for n := range int(y1-y0) + 1 {
+		i := y0 + float64(n)
+		y := float32(i * minor)
+		p := &minorPath
+		if math.Mod(i, 5) == 0 {
+			p = &majorPath
+		}
+		p.MoveTo(float32(bb.L), y)
+		p.LineTo(float32(bb.R), y)
+	}
+	t := d.Theme
+	d.strokePath(d.Screen, minorPath, t.GridMinor.R, t.GridMinor.G, t.GridMinor.B, t.GridMinor.A, w)
+	d.strokePath(d.Screen, majorPath, t.GridMajor.R, t.GridMajor.G, t.GridMajor.B, t.GridMajor.A, w)
+
+	// origin axes
+	if bb.B <= 0 && bb.T >= 0 {
+		var path vector.Path
+		path.MoveTo(float32(bb.L), 0)
+		path.LineTo(float32(bb.R), 0)
+		d.strokePath(d.Screen, path, t.GridAxisX.R, t.GridAxisX.G, t.GridAxisX.B, t.GridAxisX.A, w*2)
+	}
+	if bb.L <= 0 && bb.R >= 0 {
+		var path vector.Path
+		path.MoveTo(0, float32(bb.B))
+		path.LineTo(0, float32(bb.T))
+		d.strokePath(d.Screen, path, t.GridAxisY.R, t.GridAxisY.G, t.GridAxisY.B, t.GridAxisY.A, w*2)
+	}
+
+	if d.DrawingOptions.GridLabels {
+		d.drawGridLabels(bb, major)
+	}
+}
+
+// drawGridLabels draws the major line coordinates along the top and left screen edges.
+// The labels are placed for cameras without rotation.
+func (d *Drawer) drawGridLabels(bb cm.BB, major float64) {
+	decimals := max(0, int(-math.Floor(math.Log10(major))))
+	clr := d.Theme.GridLabel
+	// adding 0 turns -0 into 0
+	x0 := math.Ceil(bb.L / major)
+	for n := range max(int(math.Floor(bb.R/major)-x0)+1, 0) {
+		x := (x0+float64(n))*major + 0
+		sx, _ := d.GeoM.Apply(x, 0)
+		d.drawScreenText(sx+2, 0, fmt.Sprintf("%.*f", decimals, x), clr)
+	}
+	y0 := math.Ceil(bb.B / major)
+	for n := range max(int(math.Floor(bb.T/major)-y0)+1, 0) {
+		y := (y0+float64(n))*major + 0
+		_, sy := d.GeoM.Apply(0, y)
+		d.drawScreenText(2, sy, fmt.Sprintf("%.*f", decimals, y), clr)
+	}
+}
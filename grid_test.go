@@ -0,0 +1,35 @@
+package ebitencm
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGridSpacing(t *testing.T) {
+	tests := []struct {
+		pixelsPerUnit, minPixels float64
+		want                     float64
+	}{
+		{1, 16, 20},
+		{1, 10, 10},
+		{1, 20, 20},
+		{1, 21, 50},
+		{10, 16, 2},
+		{100, 16, 0.2},
+		{0.1, 16, 200},
+		{3, 16, 10},
+		// minPixels is at least 1
+		{1, 0, 1},
+		{2, -5, 0.5},
+		{1, math.NaN(), 1},
+	}
+	for _, tt := range tests {
+		got := gridSpacing(tt.pixelsPerUnit, tt.minPixels)
+		if math.Abs(got-tt.want) > 1e-9*tt.want {
+			t.Errorf("gridSpacing(%v, %v) = %v, want %v", tt.pixelsPerUnit, tt.minPixels, got, tt.want)
+		}
+		if !(got > 0) || got*tt.pixelsPerUnit < max(tt.minPixels, 1)*(1-1e-9) {
+			t.Errorf("gridSpacing(%v, %v) = %v is closer than the minimum", tt.pixelsPerUnit, tt.minPixels, got)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package ebitencm
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/setanarut/cm"
+	"github.com/setanarut/v"
+)
+
+// drawStats counts the GPU work of a DrawSpace call
+type drawStats struct {
+	triangles, drawCalls int
+}
+
+// countDraw counts a draw call of the triangles submitted since start
+func (d *Drawer) countDraw(triangles int, start time.Time) {
+	d.stats.drawCalls++
+	d.stats.triangles += triangles
+	d.profAdd(profileSubmission, start)
+}
+
+// Step steps the space and measures the step time shown in the HUD
+func (d *Drawer) Step(space *cm.Space, dt float64) {
+	start := time.Now()
+	space.Step(dt)
+	d.stepTime = time.Since(start)
+	if d.Profiler != nil {
+		d.Profiler.current[profileStep] += d.stepTime
+	}
+}
+
+// spaceCounts are the object counts shown in the HUD
+type spaceCounts struct {
+	bodies, shapes                  [4]int // static, dynamic, kinematic, sleeping
+	constraints, arbiters, contacts int
+}
+
+// countSpace counts the bodies and shapes by type, the constraints, arbiters and contacts
+func countSpace(space *cm.Space) spaceCounts {
+	var c spaceCounts
+	kind := func(body *cm.Body) int {
+		switch body.Type() {
+		case cm.Static:
+			return 0
+		case cm.Kinematic:
+			return 2
+		}
+		return 1
+	}
+	countBody := func(body *cm.Body) {
+		c.bodies[kind(body)]++
+		if body.IsSleeping() {
+			c.bodies[3]++
+		}
+	}
+	countShape := func(shape *cm.Shape) {
+		c.shapes[kind(shape.Body)]++
+		if shape.Body.IsSleeping() {
+			c.shapes[3]++
+		}
+	}
+	// the space static body is not in the static body list
+	if !slices.Contains(space.StaticBodies, space.StaticBody) {
+		countBody(space.StaticBody)
+	}
+	space.EachStaticBody(countBody)
+	space.EachDynamicBody(countBody)
+	space.EachStaticShape(countShape)
+	space.EachDynamicShape(countShape)
+	space.EachConstraint(func(*cm.Constraint) { c.constraints++ })
+	c.arbiters = len(space.Arbiters)
+	for _, arb := range space.Arbiters {
+		c.contacts += arb.Count()
+	}
+	return c
+}
+
+// drawHUD draws the statistics panel at DrawingOptions.HUDPosition in screen coordinates.
+// Draw time, triangles and draw calls are of the previous DrawSpace.
+func (d *Drawer) drawHUD(space *cm.Space) {
+	c := countSpace(space)
+	ms := func(t time.Duration) float64 { return float64(t.Microseconds()) / 1000 }
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Bodies  static %d  dynamic %d  kinematic %d  sleeping %d\n", c.bodies[0], c.bodies[1], c.bodies[2], c.bodies[3])
+	fmt.Fprintf(&sb, "Shapes  static %d  dynamic %d  kinematic %d  sleeping %d\n", c.shapes[0], c.shapes[1], c.shapes[2], c.shapes[3])
+	fmt.Fprintf(&sb, "Constraints %d\n", c.constraints)
+	fmt.Fprintf(&sb, "Arbiters %d  contacts %d\n", c.arbiters, c.contacts)
+	fmt.Fprintf(&sb, "Step %.2f ms  draw %.2f ms\n", ms(d.stepTime), ms(d.drawTime))
+	fmt.Fprintf(&sb, "Triangles %d  draw calls %d", d.lastStats.triangles, d.lastStats.drawCalls)
+	text := sb.String()
+
+	lines := strings.Split(text, "\n")
+	cols := 0
+	for _, l := range lines {
+		cols = max(cols, len(l))
+	}
+	const pad = 4
+	pos := d.DrawingOptions.HUDPosition
+	size := v.Vec{X: float64(cols*debugGlyphW + 2*pad), Y: float64(len(lines)*debugGlyphH + 2*pad)}
+	d.fillScreenRect(pos, size, d.Theme.HUDBackground)
+	d.drawScreenText(pos.X+pad, pos.Y+pad, text, d.Theme.HUDText)
+}
@@ -0,0 +1,138 @@
+package ebitencm
+
+import (
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/setanarut/cm"
+)
+
+// Layer identifies a group of draw calls made by DrawSpace
+type Layer int
+
+const (
+	LayerSprites Layer = iota
+	LayerStatic
+	LayerDynamic
+	LayerConstraints
+	LayerCollisions
+	LayerQueries
+	LayerDebug
+	LayerTrails
+	LayerGrid
+	LayerHUD
+	LayerGraphs
+	LayerProfiler
+	LayerEffects
+
+	// layerCustom is the first Layer returned by AddLayer
+	layerCustom Layer = 100
+	// layerNone is the layer outside of DrawSpace, it has no options
+	layerNone Layer = -1
+)
+
+// LayerFunc draws a custom layer. Drawer methods and Drawer.GeoM can be used inside.
+type LayerFunc func(space *cm.Space, screen *ebiten.Image)
+
+// DefaultLayerOrder returns the built-in layers in their default draw order
+func DefaultLayerOrder() []Layer {
+	return []Layer{LayerGrid, LayerEffects, LayerSprites, LayerTrails, LayerStatic, LayerDynamic, LayerConstraints, LayerCollisions, LayerQueries, LayerDebug, LayerGraphs, LayerHUD, LayerProfiler}
+}
+
+// AddLayer adds a custom layer drawn right after the layer in Drawer.LayerOrder and returns it.
+// If after is not in the order, the layer is drawn last.
+func (d *Drawer) AddLayer(after Layer, fn LayerFunc) Layer {
+	if d.customLayers == nil {
+		d.customLayers = make(map[Layer]LayerFunc)
+	}
+	layer := layerCustom
+	for d.customLayers[layer] != nil {
+		layer++
+	}
+	d.customLayers[layer] = fn
+	i := slices.Index(d.LayerOrder, after)
+	if i < 0 {
+		d.LayerOrder = append(d.LayerOrder, layer)
+	} else {
+		d.LayerOrder = slices.Insert(d.LayerOrder, i+1, layer)
+	}
+	return layer
+}
+
+// RemoveLayer removes the custom layer
+func (d *Drawer) RemoveLayer(layer Layer) {
+	delete(d.customLayers, layer)
+	delete(d.layers, layer)
+	d.LayerOrder = slices.DeleteFunc(d.LayerOrder, func(l Layer) bool { return l == layer })
+}
+
+// layerOrder returns Drawer.LayerOrder with DrawingOptions.ConstraintsUnderBodies applied
+func (d *Drawer) layerOrder() []Layer {
+	if !d.DrawingOptions.ConstraintsUnderBodies {
+		return d.LayerOrder
+	}
+	bodies := slices.IndexFunc(d.LayerOrder, func(l Layer) bool { return l == LayerStatic || l == LayerDynamic })
+	constraints := slices.Index(d.LayerOrder, LayerConstraints)
+	if bodies < 0 || constraints < bodies {
+		return d.LayerOrder
+	}
+	d.order = append(d.order[:0], d.LayerOrder...)
+	d.order = slices.Delete(d.order, constraints, constraints+1)
+	return slices.Insert(d.order, bodies, LayerConstraints)
+}
+
+// LayerOptions are the draw settings of a layer
+type LayerOptions struct {
+	// Blend of the layer triangles and sprites. The zero value keeps the draw options blend.
+	Blend ebiten.Blend
+	// ColorScale multiplies the colors of the layer (premultiplied)
+	ColorScale ebiten.ColorScale
+	// Filter of sprites and textured fills. nil keeps the draw options filter.
+	Filter *ebiten.Filter
+}
+
+// SetLayerOptions sets the draw settings of the layer. nil restores the shared settings.
+func (d *Drawer) SetLayerOptions(layer Layer, opts *LayerOptions) {
+	if opts == nil {
+		delete(d.layers, layer)
+		return
+	}
+	if d.layers == nil {
+		d.layers = make(map[Layer]*LayerOptions)
+	}
+	d.layers[layer] = opts
+}
+
+// GetLayerOptions returns the draw settings of the layer or nil
+func (d *Drawer) GetLayerOptions(layer Layer) *LayerOptions {
+	return d.layers[layer]
+}
+
+// layerTriangleOptions returns op with the blend and filter set by the current layer
+func (d *Drawer) layerTriangleOptions(op *ebiten.DrawTrianglesOptions) *ebiten.DrawTrianglesOptions {
+	lo := d.layers[d.layer]
+	if lo == nil || lo.Blend == (ebiten.Blend{}) && lo.Filter == nil {
+		return op
+	}
+	o := *op
+	if lo.Blend != (ebiten.Blend{}) {
+		o.Blend = lo.Blend
+	}
+	if lo.Filter != nil {
+		o.Filter = *lo.Filter
+	}
+	return &o
+}
+
+// layerColor applies the color scale of the current layer to the straight-alpha color
+func (d *Drawer) layerColor(r, g, b, a float32) (float32, float32, float32, float32) {
+	lo := d.layers[d.layer]
+	if lo == nil {
+		return r, g, b, a
+	}
+	cs := lo.ColorScale
+	if cs.A() == 0 {
+		return 0, 0, 0, 0
+	}
+	return r * cs.R() / cs.A(), g * cs.G() / cs.A(), b * cs.B() / cs.A(), a * cs.A()
+}
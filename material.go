@@ -0,0 +1,70 @@
+package ebitencm
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/setanarut/cm"
+)
+
+// Material renders the triangles of a shape with a Kage shader instead of a flat color.
+//
+// The shader receives the world position of the fragment in srcPos, the fill or stroke
+// color in color and the body-local position in custom.xy:
+//
+//	func Fragment(dstPos vec4, srcPos vec2, color vec4, custom vec4) vec4
+//
+// These per-shape uniforms are set if the shader declares them:
+//
+//	var Position vec2         // body position
+//	var Velocity vec2         // body velocity
+//	var Angle float           // body angle
+//	var AngularVelocity float // body angular velocity
+type Material struct {
+	Shader *ebiten.Shader
+	// Uniforms passed to the shader with the per-shape uniforms
+	Uniforms map[string]any
+	// Images passed to the shader
+	Images [4]*ebiten.Image
+}
+
+// drawTrianglesMaterial draws the world space vertices with the material shader
+func (d *Drawer) drawTrianglesMaterial(screen *ebiten.Image, vs []ebiten.Vertex, is []uint16, m *Material, r, g, b, a float32, triOp *ebiten.DrawTrianglesOptions) {
+	tess := d.profStart()
+	body := d.currentBody
+	for i := range vs {
+		vs[i].SrcX, vs[i].SrcY = vs[i].DstX, vs[i].DstY
+		if body != nil {
+			local := body.WorldToLocal(vec(vs[i].DstX, vs[i].DstY))
+			vs[i].Custom0, vs[i].Custom1 = float32(local.X), float32(local.Y)
+		}
+	}
+	transformVertices(vs, d.GeoM, r, g, b, a)
+	d.profAdd(profileTessellation, tess)
+	op := &ebiten.DrawTrianglesShaderOptions{
+		Images:    m.Images,
+		Uniforms:  d.materialUniforms(m, body),
+		AntiAlias: triOp.AntiAlias,
+		Blend:     triOp.Blend,
+	}
+	submit := d.profStart()
+	screen.DrawTrianglesShader(vs, is, m.Shader, op)
+	d.countDraw(len(is)/3, submit)
+}
+
+// materialUniforms returns the material uniforms with the per-shape uniforms of the body
+func (d *Drawer) materialUniforms(m *Material, body *cm.Body) map[string]any {
+	if d.uniforms == nil {
+		d.uniforms = make(map[string]any)
+	}
+	clear(d.uniforms)
+	for k, u := range m.Uniforms {
+		d.uniforms[k] = u
+	}
+	if body != nil {
+		pos, vel := body.Position(), body.Velocity()
+		d.uniforms["Position"] = []float32{float32(pos.X), float32(pos.Y)}
+		d.uniforms["Velocity"] = []float32{float32(vel.X), float32(vel.Y)}
+		d.uniforms["Angle"] = float32(body.Angle())
+		d.uniforms["AngularVelocity"] = float32(body.AngularVelocity())
+	}
+	return d.uniforms
+}
@@ -0,0 +1,189 @@
+package ebitencm
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/setanarut/cm"
+	"github.com/setanarut/v"
+)
+
+type profilePhase int
+
+const (
+	profileStep profilePhase = iota
+	profileMouse
+	profileDraw
+	profileIteration
+	profileTessellation
+	profileSubmission
+	profilePhaseCount
+)
+
+var profileLabels = [profilePhaseCount]string{"Step", "Mouse", "Draw", "Iteration", "Tessellation", "Submission"}
+
+// profileColors of the flame bar segments
+var profileColors = [profilePhaseCount]cm.FColor{
+	{R: 0.902, G: 0.624, B: 0, A: 1},
+	{R: 0.8, G: 0.475, B: 0.655, A: 1},
+	{R: 0.5, G: 0.5, B: 0.5, A: 1},
+	{R: 0.337, G: 0.706, B: 0.914, A: 1},
+	{R: 0, G: 0.62, B: 0.451, A: 1},
+	{R: 0.941, G: 0.894, B: 0.259, A: 1},
+}
+
+// ProfileStats are the durations of a phase over the profiler window
+type ProfileStats struct {
+	Min, Avg, Max time.Duration
+}
+
+// Profile is the per-frame time of each phase over the profiler window.
+// The phases called several times in a frame are summed.
+type Profile struct {
+	// Frames is the number of frames in the window
+	Frames int
+	// Step is the time of Drawer.Step
+	Step ProfileStats
+	// HandleMouseEvent is the time of Drawer.HandleMouseEvent
+	HandleMouseEvent ProfileStats
+	// DrawSpace is the time of Drawer.DrawSpace, the sum of the three stages below
+	DrawSpace ProfileStats
+	// Iteration is the DrawSpace time spent walking the space, resolving styles, sorting and building paths
+	Iteration ProfileStats
+	// Tessellation is the DrawSpace time spent converting paths to triangles and transforming vertices
+	Tessellation ProfileStats
+	// Submission is the DrawSpace time spent in Ebitengine draw calls
+	Submission ProfileStats
+}
+
+// Profiler times the drawer phases. Set it as Drawer.Profiler and step the space with Drawer.Step.
+type Profiler struct {
+	// Window is the number of frames aggregated
+	Window int
+	// Overlay draws the flame bar and the statistics in LayerProfiler
+	Overlay bool
+	// Position of the overlay in screen coordinates
+	Position v.Vec
+	// Width of the flame bar representing Budget
+	Width float64
+	// Budget is the frame time represented by the full bar width
+	Budget time.Duration
+
+	frames  [][profilePhaseCount]time.Duration
+	head    int
+	count   int
+	current [profilePhaseCount]time.Duration
+}
+
+// NewProfiler returns a profiler aggregating 120 frames with a 1/60 second budget
+func NewProfiler() *Profiler {
+	return &Profiler{
+		Window:   120,
+		Position: v.Vec{X: 8, Y: 8},
+		Width:    300,
+		Budget:   time.Second / 60,
+	}
+}
+
+// Reset removes all recorded frames
+func (p *Profiler) Reset() {
+	p.head, p.count = 0, 0
+	p.current = [profilePhaseCount]time.Duration{}
+}
+
+// endFrame records the current frame with the DrawSpace time
+func (p *Profiler) endFrame(draw time.Duration) {
+	if p.Window <= 0 {
+		return
+	}
+	if len(p.frames) != p.Window {
+		p.frames = make([][profilePhaseCount]time.Duration, p.Window)
+		p.head, p.count = 0, 0
+	}
+	f := p.current
+	f[profileDraw] = draw
+	f[profileIteration] = max(draw-f[profileTessellation]-f[profileSubmission], 0)
+	p.frames[p.head] = f
+	p.head = (p.head + 1) % p.Window
+	p.count = min(p.count+1, p.Window)
+	p.current = [profilePhaseCount]time.Duration{}
+}
+
+// stats returns the min, avg and max of the phase over the window
+func (p *Profiler) stats(phase profilePhase) ProfileStats {
+	if p.count == 0 {
+		return ProfileStats{}
+	}
+	s := ProfileStats{Min: p.frames[0][phase]}
+	var sum time.Duration
+	for i := range p.count {
+		t := p.frames[i][phase]
+		s.Min, s.Max = min(s.Min, t), max(s.Max, t)
+		sum += t
+	}
+	s.Avg = sum / time.Duration(p.count)
+	return s
+}
+
+// Profile returns the phase statistics over the window
+func (p *Profiler) Profile() Profile {
+	return Profile{
+		Frames:           p.count,
+		Step:             p.stats(profileStep),
+		HandleMouseEvent: p.stats(profileMouse),
+		DrawSpace:        p.stats(profileDraw),
+		Iteration:        p.stats(profileIteration),
+		Tessellation:     p.stats(profileTessellation),
+		Submission:       p.stats(profileSubmission),
+	}
+}
+
+// profStart returns the current time when profiling
+func (d *Drawer) profStart() time.Time {
+	if d.Profiler == nil {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// profAdd adds the time since start to the phase of the current frame
+func (d *Drawer) profAdd(phase profilePhase, start time.Time) {
+	if d.Profiler != nil && !start.IsZero() {
+		d.Profiler.current[phase] += time.Since(start)
+	}
+}
+
+// drawProfiler draws the average phase times as a flame bar scaled to the budget, and the statistics below
+func (d *Drawer) drawProfiler() {
+	p := d.Profiler
+	pos := p.Position
+	const barH = 12
+	d.fillScreenRect(pos, v.Vec{X: p.Width, Y: barH}, d.Theme.HUDBackground)
+
+	var sb strings.Builder
+	ms := func(t time.Duration) float64 { return float64(t.Microseconds()) / 1000 }
+	x := pos.X
+	for phase := range profilePhaseCount {
+		s := p.stats(phase)
+		fmt.Fprintf(&sb, "%-12s avg %6.2f  min %6.2f  max %6.2f ms\n", profileLabels[phase], ms(s.Avg), ms(s.Min), ms(s.Max))
+		// DrawSpace is shown by its stages
+		if phase == profileDraw || p.Budget <= 0 {
+			continue
+		}
+		w := p.Width * float64(s.Avg) / float64(p.Budget)
+		if w > 0 {
+			d.fillScreenRect(v.Vec{X: x, Y: pos.Y}, v.Vec{X: w, Y: barH}, profileColors[phase])
+			x += w
+		}
+	}
+	text := strings.TrimSuffix(sb.String(), "\n")
+	lines := strings.Split(text, "\n")
+	cols := 0
+	for _, l := range lines {
+		cols = max(cols, len(l))
+	}
+	textPos := v.Vec{X: pos.X, Y: pos.Y + barH + 2}
+	d.fillScreenRect(textPos, v.Vec{X: float64(cols * debugGlyphW), Y: float64(len(lines) * debugGlyphH)}, d.Theme.HUDBackground)
+	d.drawScreenText(textPos.X, textPos.Y, text, d.Theme.HUDText)
+}
@@ -0,0 +1,233 @@
+package ebitencm
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/setanarut/cm"
+	"github.com/setanarut/v"
+)
+
+type queryKind int
+
+const (
+	querySegment queryKind = iota
+	queryPoint
+	queryShape
+	queryBB
+)
+
+// queryHit is a recorded query result. shape is only recorded for BB queries.
+type queryHit struct {
+	shape         shapeSnapshot
+	point, normal v.Vec
+}
+
+// shapeSnapshot is the world geometry of a shape at query time
+type shapeSnapshot struct {
+	class  any // *cm.Circle, *cm.Segment or *cm.PolyShape
+	verts  []v.Vec
+	radius float64
+	angle  float64
+}
+
+// snapshotShape copies the world geometry of the shape. Shapes without a body keep their last transformed geometry.
+func snapshotShape(shape *cm.Shape) shapeSnapshot {
+	s := shapeSnapshot{class: shape.Class}
+	if shape.Body != nil {
+		s.angle = shape.Body.Angle()
+	}
+	switch c := shape.Class.(type) {
+	case *cm.Circle:
+		s.verts, s.radius = []v.Vec{c.TransformC()}, c.Radius()
+	case *cm.Segment:
+		s.verts, s.radius = []v.Vec{c.TransformA(), c.TransformB()}, c.Radius()
+	case *cm.PolyShape:
+		s.verts, s.radius = make([]v.Vec, c.Count()), c.Radius
+		for i := range s.verts {
+			s.verts[i] = c.Planes[i].V0
+		}
+	}
+	return s
+}
+
+// recordedQuery is a space query with its results
+type recordedQuery struct {
+	kind   queryKind
+	a, b   v.Vec
+	radius float64
+	bb     cm.BB
+	shape  shapeSnapshot
+	hits   []queryHit
+	age    int
+}
+
+// QueryRecorder wraps the cm.Space queries and records them for the drawer.
+// Set it as Drawer.QueryRecorder to draw the recorded queries.
+type QueryRecorder struct {
+	Space *cm.Space
+	// Frames is the number of DrawSpace calls a query stays visible
+	Frames int
+	// Disabled stops recording, the queries still run
+	Disabled bool
+
+	queries []recordedQuery
+}
+
+// NewQueryRecorder returns a recorder of the space queries visible for 30 frames
+func NewQueryRecorder(space *cm.Space) *QueryRecorder {
+	return &QueryRecorder{Space: space, Frames: 30}
+}
+
+// record appends the query unless disabled
+func (r *QueryRecorder) record(q recordedQuery) {
+	if !r.Disabled {
+		r.queries = append(r.queries, q)
+	}
+}
+
+// Clear removes all recorded queries
+func (r *QueryRecorder) Clear() {
+	clear(r.queries)
+	r.queries = r.queries[:0]
+}
+
+// SegmentQuery runs and records cm.Space.SegmentQuery
+func (r *QueryRecorder) SegmentQuery(start, end v.Vec, radius float64, filter cm.ShapeFilter, f cm.SpaceSegmentQueryFunc, data any) {
+	q := recordedQuery{kind: querySegment, a: start, b: end, radius: radius}
+	r.Space.SegmentQuery(start, end, radius, filter, func(shape *cm.Shape, point, normal v.Vec, alpha float64, data any) {
+		q.hits = append(q.hits, queryHit{point: point, normal: normal})
+		if f != nil {
+			f(shape, point, normal, alpha, data)
+		}
+	}, data)
+	r.record(q)
+}
+
+// SegmentQueryFirst runs and records cm.Space.SegmentQueryFirst
+func (r *QueryRecorder) SegmentQueryFirst(start, end v.Vec, radius float64, filter cm.ShapeFilter) cm.SegmentQueryInfo {
+	info := r.Space.SegmentQueryFirst(start, end, radius, filter)
+	q := recordedQuery{kind: querySegment, a: start, b: end, radius: radius}
+	if info.Shape != nil {
+		q.hits = []queryHit{{point: info.Point, normal: info.Normal}}
+	}
+	r.record(q)
+	return info
+}
+
+// PointQueryNearest runs and records cm.Space.PointQueryNearest
+func (r *QueryRecorder) PointQueryNearest(point v.Vec, maxDistance float64, filter cm.ShapeFilter) *cm.PointQueryInfo {
+	info := r.Space.PointQueryNearest(point, maxDistance, filter)
+	q := recordedQuery{kind: queryPoint, a: point, radius: maxDistance}
+	if info.Shape != nil {
+		q.hits = []queryHit{{point: info.Point, normal: info.Gradient}}
+	}
+	r.record(q)
+	return info
+}
+
+// ShapeQuery runs and records cm.Space.ShapeQuery
+func (r *QueryRecorder) ShapeQuery(shape *cm.Shape, callback func(shape *cm.Shape, points *cm.ContactPointSet)) bool {
+	q := recordedQuery{kind: queryShape, shape: snapshotShape(shape)}
+	hit := r.Space.ShapeQuery(shape, func(other *cm.Shape, points *cm.ContactPointSet) {
+		for i := range points.Count {
+			q.hits = append(q.hits, queryHit{point: points.Points[i].PointB, normal: points.Normal})
+		}
+		if callback != nil {
+			callback(other, points)
+		}
+	})
+	r.record(q)
+	return hit
+}
+
+// BBQuery runs and records cm.Space.BBQuery
+func (r *QueryRecorder) BBQuery(bb cm.BB, filter cm.ShapeFilter, f cm.SpaceBBQueryFunc, data any) {
+	q := recordedQuery{kind: queryBB, bb: bb}
+	r.Space.BBQuery(bb, filter, func(shape *cm.Shape, data any) {
+		q.hits = append(q.hits, queryHit{shape: snapshotShape(shape)})
+		if f != nil {
+			f(shape, data)
+		}
+	}, data)
+	r.record(q)
+}
+
+// drawQueries draws the recorded queries and removes the expired ones
+func (d *Drawer) drawQueries() {
+	r := d.QueryRecorder
+	if r == nil {
+		return
+	}
+	for _, q := range r.queries {
+		d.drawQuery(&q)
+	}
+	frames := max(r.Frames, 1)
+	n := 0
+	for _, q := range r.queries {
+		q.age++
+		if q.age < frames {
+			r.queries[n] = q
+			n++
+		}
+	}
+	clear(r.queries[n:])
+	r.queries = r.queries[:n]
+}
+
+// drawQuery draws the query area, hit points and hit normals
+func (d *Drawer) drawQuery(q *recordedQuery) {
+	stroke, hit := d.Theme.QueryStroke, d.Theme.QueryHit
+	w := d.DrawingOptions.QueryStrokeWidth
+	none := cm.FColor{}
+	dot := float64(w) * 2
+	switch q.kind {
+	case querySegment:
+		if q.radius > 0 {
+			d.drawFatSegment(q.a, q.b, q.radius, stroke, none, w)
+		} else {
+			d.drawSegment(q.a, q.b, stroke, w)
+		}
+	case queryPoint:
+		d.drawDot(dot, q.a, stroke)
+		if q.radius > 0 && !math.IsInf(q.radius, 1) {
+			d.drawRing(q.a, q.radius, stroke, w)
+		}
+		for _, h := range q.hits {
+			d.drawSegment(q.a, h.point, hit, w)
+		}
+	case queryShape:
+		d.drawShapeSnapshot(&q.shape, stroke, none, w)
+	case queryBB:
+		bb := q.bb
+		d.drawPolygon(4, []v.Vec{{X: bb.L, Y: bb.B}, {X: bb.R, Y: bb.B}, {X: bb.R, Y: bb.T}, {X: bb.L, Y: bb.T}}, 0, stroke, none, w)
+		for _, h := range q.hits {
+			d.drawShapeSnapshot(&h.shape, hit, none, w)
+		}
+		return
+	}
+	for _, h := range q.hits {
+		d.drawDot(dot, h.point, hit)
+		d.drawArrow(h.point, h.point.Add(h.normal.Scale(d.DrawingOptions.CollisionNormalLength)), hit, w)
+	}
+}
+
+// drawShapeSnapshot draws the recorded geometry of a shape
+func (d *Drawer) drawShapeSnapshot(s *shapeSnapshot, outline, fill cm.FColor, strokeWidth float32) {
+	switch s.class.(type) {
+	case *cm.Circle:
+		d.drawCircle(s.verts[0], s.angle, s.radius, outline, fill, strokeWidth)
+	case *cm.Segment:
+		d.drawFatSegment(s.verts[0], s.verts[1], s.radius, outline, fill, strokeWidth)
+	case *cm.PolyShape:
+		d.drawPolygon(len(s.verts), s.verts, s.radius, outline, fill, strokeWidth)
+	}
+}
+
+// drawRing strokes a circle outline
+func (d *Drawer) drawRing(center v.Vec, radius float64, clr cm.FColor, strokeWidth float32) {
+	path := vector.Path{}
+	path.Arc(float32(center.X), float32(center.Y), float32(radius), 0, 2*math.Pi, vector.Clockwise)
+	path.Close()
+	d.strokePath(d.Screen, path, clr.R, clr.G, clr.B, clr.A, strokeWidth)
+}
@@ -0,0 +1,122 @@
+package ebitencm
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/setanarut/cm"
+	"github.com/setanarut/v"
+)
+
+// sdfShaderSrc renders a capsule from (0, 0) to (L, 0) with radius 1 in srcPos space.
+//
+//	custom.x: L, negative for circles with an angle indicator
+//	custom.y: stroke width / radius
+//	custom.z: packed stroke RGB
+//	custom.w: stroke alpha
+var sdfShaderSrc = []byte(`//kage:unit pixels
+
+package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4, custom vec4) vec4 {
+	l := max(custom.x, 0)
+	halfWidth := custom.y / 2
+	p := srcPos
+
+	d := length(vec2(p.x-clamp(p.x, 0, l), p.y)) - 1
+	aa := fwidth(d)
+	fillCov := clamp(0.5-d/aa, 0, 1)
+	strokeCov := clamp(0.5-(abs(d)-halfWidth)/aa, 0, 1)
+	if custom.x < 0 {
+		dl := length(vec2(p.x-clamp(p.x, 0, 1), p.y))
+		strokeCov = max(strokeCov, clamp(0.5-(dl-halfWidth)/aa, 0, 1))
+	}
+
+	packed := floor(custom.z + 0.5)
+	stroke := vec3(mod(packed, 256), mod(floor(packed/256), 256), floor(packed/65536)) / 255
+	sa := custom.w * strokeCov
+	fa := color.a * fillCov
+	return vec4(stroke*sa+color.rgb*fa*(1-sa), sa+fa*(1-sa))
+}
+`)
+
+var sdfShader *ebiten.Shader
+
+// SetSDFShapes enables drawing circles, fat segments and dots as single quads shaded
+// by a signed distance field instead of tessellated paths.
+//
+// Textured, material and dashed shapes are always drawn as paths.
+func (d *Drawer) SetSDFShapes(enabled bool) {
+	if enabled && sdfShader == nil {
+		s, err := ebiten.NewShader(sdfShaderSrc)
+		if err != nil {
+			panic(err)
+		}
+		sdfShader = s
+	}
+	d.sdf = enabled
+}
+
+// useSDF reports whether the current shape can be drawn with the SDF shader
+func (d *Drawer) useSDF(radius float64) bool {
+	return d.sdf && radius > 0 && d.fillTexture == nil && d.fillMat == nil && d.strokeMat == nil && d.strokeDash.IsSolid()
+}
+
+// sdfColors hides the fill or the stroke when they are disabled in DrawingOptions
+func (d *Drawer) sdfColors(outline, fill cm.FColor) (cm.FColor, cm.FColor) {
+	if d.DrawingOptions.AllFillsDisabled {
+		fill.A = 0
+	}
+	if d.DrawingOptions.AllStrokesDisabled {
+		outline.A = 0
+	}
+	return outline, fill
+}
+
+// drawSDFCapsule draws a capsule from a to b as a single quad.
+// indicator draws the angle indicator line of circles, the direction is given by angle.
+func (d *Drawer) drawSDFCapsule(a, b v.Vec, angle, radius float64, indicator bool, outline, fill cm.FColor, strokeWidth float32) {
+	l := a.Dist(b) / radius
+	if l > 0 {
+		angle = math.Atan2(b.Y-a.Y, b.X-a.X)
+	}
+	custom0 := float32(l)
+	if indicator {
+		custom0 = -1
+	}
+	w := float64(strokeWidth) / radius
+
+	// a margin of 2 pixels for the anti-aliased edge
+	scale := math.Sqrt(math.Abs(d.GeoM.Element(0, 0)*d.GeoM.Element(1, 1) - d.GeoM.Element(0, 1)*d.GeoM.Element(1, 0)))
+	margin := 2.0
+	if scale > 0 {
+		margin /= scale
+	}
+	e := 1 + w/2 + margin/radius
+
+	fill.R, fill.G, fill.B, fill.A = d.layerColor(fill.R, fill.G, fill.B, fill.A)
+	outline.R, outline.G, outline.B, outline.A = d.layerColor(outline.R, outline.G, outline.B, outline.A)
+	packed := float32(math.Round(float64(outline.R)*255)) +
+		float32(math.Round(float64(outline.G)*255))*256 +
+		float32(math.Round(float64(outline.B)*255))*65536
+
+	corners := [4]v.Vec{{X: -e, Y: -e}, {X: l + e, Y: -e}, {X: l + e, Y: e}, {X: -e, Y: e}}
+	var vs [4]ebiten.Vertex
+	for i, c := range corners {
+		world := a.Add(c.Scale(radius).Rotate(angle))
+		x, y := d.GeoM.Apply(world.X, world.Y)
+		vs[i] = ebiten.Vertex{
+			DstX: float32(x), DstY: float32(y),
+			SrcX: float32(c.X), SrcY: float32(c.Y),
+			ColorR: fill.R, ColorG: fill.G, ColorB: fill.B, ColorA: fill.A,
+			Custom0: custom0, Custom1: float32(w), Custom2: packed, Custom3: outline.A,
+		}
+	}
+	op := &ebiten.DrawTrianglesShaderOptions{}
+	if lo := d.layers[d.layer]; lo != nil {
+		op.Blend = lo.Blend
+	}
+	submit := d.profStart()
+	d.Screen.DrawTrianglesShader(vs[:], []uint16{0, 1, 2, 0, 2, 3}, sdfShader, op)
+	d.countDraw(2, submit)
+}
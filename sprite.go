@@ -0,0 +1,85 @@
+package ebitencm
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/setanarut/cm"
+	"github.com/setanarut/v"
+)
+
+// Sprite is an image drawn with the position and angle of a body
+type Sprite struct {
+	// Image or sub-image to draw
+	Image *ebiten.Image
+	// Offset of the pivot from the body position in body-local coordinates
+	Offset v.Vec
+	// Scale of the image
+	Scale v.Vec
+	// Pivot is the point of the image in pixels placed at the body position + Offset
+	Pivot v.Vec
+}
+
+// NewSprite returns a sprite of the image with centered pivot and scale 1
+func NewSprite(img *ebiten.Image) *Sprite {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	return &Sprite{
+		Image: img,
+		Scale: v.Vec{X: 1, Y: 1},
+		Pivot: v.Vec{X: float64(w) / 2, Y: float64(h) / 2},
+	}
+}
+
+// SetBodySprite attaches the sprite to the body. nil removes the sprite.
+func (d *Drawer) SetBodySprite(body *cm.Body, sprite *Sprite) {
+	if sprite == nil {
+		delete(d.bodySprites, body)
+		return
+	}
+	if d.bodySprites == nil {
+		d.bodySprites = make(map[*cm.Body]*Sprite)
+	}
+	d.bodySprites[body] = sprite
+}
+
+// BodySprite returns the sprite of the body or nil
+func (d *Drawer) BodySprite(body *cm.Body) *Sprite {
+	return d.bodySprites[body]
+}
+
+// drawSprites draws the sprites of static bodies, then dynamic bodies in space order
+func (d *Drawer) drawSprites(space *cm.Space) {
+	if len(d.bodySprites) == 0 {
+		return
+	}
+	drawBody := func(body *cm.Body) {
+		if s, ok := d.bodySprites[body]; ok {
+			d.drawSprite(body, s)
+		}
+	}
+	space.EachStaticBody(drawBody)
+	space.EachDynamicBody(drawBody)
+}
+
+// drawSprite draws the sprite with the body transform and Drawer.GeoM
+func (d *Drawer) drawSprite(body *cm.Body, s *Sprite) {
+	if s.Image == nil {
+		return
+	}
+	pos := body.Position()
+	op := &ebiten.DrawImageOptions{Filter: ebiten.FilterLinear}
+	if lo := d.layers[LayerSprites]; lo != nil {
+		op.Blend = lo.Blend
+		op.ColorScale = lo.ColorScale
+		if lo.Filter != nil {
+			op.Filter = *lo.Filter
+		}
+	}
+	op.GeoM.Translate(-s.Pivot.X, -s.Pivot.Y)
+	op.GeoM.Scale(s.Scale.X, s.Scale.Y)
+	op.GeoM.Translate(s.Offset.X, s.Offset.Y)
+	op.GeoM.Rotate(body.Angle())
+	op.GeoM.Translate(pos.X, pos.Y)
+	op.GeoM.Concat(*d.GeoM)
+	submit := d.profStart()
+	d.Screen.DrawImage(s.Image, op)
+	d.countDraw(2, submit)
+}
@@ -0,0 +1,185 @@
+package ebitencm
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/setanarut/cm"
+)
+
+// Style overrides the Theme colors and DrawingOptions stroke widths for a single body or shape.
+//
+// Nil colors and a zero StrokeWidth fall back to the Drawer defaults.
+type Style struct {
+	// Fill color of the shape
+	Fill *cm.FColor
+	// Stroke (outline) color of the shape
+	Stroke *cm.FColor
+	// Stroke width, 0 uses the DrawingOptions width
+	StrokeWidth float32
+	// Stroke dash pattern, the zero value uses the default stroke
+	Dash Dash
+	// FillTexture is a repeating image multiplied by the fill color.
+	// When Fill is nil the texture is drawn untinted.
+	FillTexture *Texture
+	// FillMaterial draws the fill with a shader, it replaces FillTexture
+	FillMaterial *Material
+	// StrokeMaterial draws the stroke with a shader
+	StrokeMaterial *Material
+	// Hidden shapes are not drawn by DrawSpace
+	Hidden bool
+	// Shapes with a higher ZIndex are drawn over shapes with a lower one in the same pass
+	ZIndex int
+}
+
+// SetBodyStyle sets the style used for all shapes of the body. nil removes the style.
+func (d *Drawer) SetBodyStyle(body *cm.Body, style *Style) {
+	if style == nil {
+		delete(d.bodyStyles, body)
+		return
+	}
+	if d.bodyStyles == nil {
+		d.bodyStyles = make(map[*cm.Body]*Style)
+	}
+	d.bodyStyles[body] = style
+}
+
+// SetShapeStyle sets the style of the shape. A shape style replaces the style of its body. nil removes the style.
+func (d *Drawer) SetShapeStyle(shape *cm.Shape, style *Style) {
+	if style == nil {
+		delete(d.shapeStyles, shape)
+		return
+	}
+	if d.shapeStyles == nil {
+		d.shapeStyles = make(map[*cm.Shape]*Style)
+	}
+	d.shapeStyles[shape] = style
+}
+
+// BodyStyle returns the style of the body or nil
+func (d *Drawer) BodyStyle(body *cm.Body) *Style {
+	return d.bodyStyles[body]
+}
+
+// ShapeStyle returns the style of the shape or nil
+func (d *Drawer) ShapeStyle(shape *cm.Shape) *Style {
+	return d.shapeStyles[shape]
+}
+
+// Forget removes the style, sprite and trail of the body and the styles of its shapes.
+// The drawer keeps references to the bodies and shapes given to the Set functions,
+// call Forget when a body is removed from the space so they can be collected.
+func (d *Drawer) Forget(body *cm.Body) {
+	delete(d.bodyStyles, body)
+	delete(d.bodySprites, body)
+	delete(d.bodyTrails, body)
+	for _, shape := range body.Shapes {
+		d.ForgetShape(shape)
+	}
+}
+
+// ForgetShape removes the style of the shape. Call it when a shape is removed from the space.
+func (d *Drawer) ForgetShape(shape *cm.Shape) {
+	delete(d.shapeStyles, shape)
+}
+
+// ClearStyles removes all body and shape styles
+func (d *Drawer) ClearStyles() {
+	clear(d.bodyStyles)
+	clear(d.shapeStyles)
+}
+
+// styleOf returns the shape style, the body style or nil
+func (d *Drawer) styleOf(shape *cm.Shape) *Style {
+	if s, ok := d.shapeStyles[shape]; ok {
+		return s
+	}
+	return d.bodyStyles[shape.Body]
+}
+
+// shapeItem is a shape with resolved colors waiting to be drawn
+type shapeItem struct {
+	shape         *cm.Shape
+	outline, fill cm.FColor
+	strokeWidth   float32
+	z             int
+	dash          Dash
+	texture       *Texture
+	fillMat       *Material
+	strokeMat     *Material
+}
+
+// queueShape resolves the shape style over the given defaults and queues the shape for flushShapes
+func (d *Drawer) queueShape(shape *cm.Shape, outline, fill cm.FColor, strokeWidth float32) {
+	item := shapeItem{shape: shape, outline: outline, fill: fill, strokeWidth: strokeWidth}
+	if shape.Sensor {
+		if d.DrawingOptions.SensorDisabled || d.DrawingOptions.SensorOccupiedOnly && !sensorOccupied(shape) {
+			return
+		}
+		item.outline = d.Theme.SensorStroke
+		item.fill = d.Theme.SensorFill
+		item.strokeWidth = d.DrawingOptions.SensorStrokeWidth
+		item.dash = d.DrawingOptions.SensorDash
+	}
+	if s := d.styleOf(shape); s != nil {
+		if s.Hidden {
+			return
+		}
+		if s.FillTexture != nil {
+			item.texture = s.FillTexture
+			item.fill = cm.FColor{R: 1, G: 1, B: 1, A: item.fill.A}
+		}
+		if s.Fill != nil {
+			item.fill = *s.Fill
+		}
+		if s.Stroke != nil {
+			item.outline = *s.Stroke
+		}
+		if s.StrokeWidth != 0 {
+			item.strokeWidth = s.StrokeWidth
+		}
+		item.z = s.ZIndex
+		item.fillMat = s.FillMaterial
+		item.strokeMat = s.StrokeMaterial
+		if len(s.Dash.Pattern) > 0 {
+			item.dash = s.Dash
+		}
+	}
+	d.shapeQueue = append(d.shapeQueue, item)
+}
+
+// flushShapes draws the queued shapes ordered by z-index, then by the order they were added to the space
+func (d *Drawer) flushShapes() {
+	slices.SortStableFunc(d.shapeQueue, func(a, b shapeItem) int {
+		if c := cmp.Compare(a.z, b.z); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.shape.HashId(), b.shape.HashId())
+	})
+	for _, it := range d.shapeQueue {
+		d.strokeDash = it.dash
+		d.fillTexture, d.currentBody = it.texture, it.shape.Body
+		d.fillMat, d.strokeMat = it.fillMat, it.strokeMat
+		d.drawShape(it.shape, it.outline, it.fill, it.strokeWidth)
+		d.strokeDash = Dash{}
+		d.fillTexture, d.currentBody = nil, nil
+		d.fillMat, d.strokeMat = nil, nil
+	}
+	clear(d.shapeQueue)
+	d.shapeQueue = d.shapeQueue[:0]
+}
+
+// sensorOccupied reports whether a non-sensor shape of another non-static body overlaps the sensor.
+// Static shapes such as walls touching a trigger do not occupy it. Sensors outside a space are never occupied.
+func sensorOccupied(sensor *cm.Shape) bool {
+	if sensor.Space == nil {
+		return false
+	}
+	occupied := false
+	sensor.Space.ShapeQuery(sensor, func(shape *cm.Shape, _ *cm.ContactPointSet) {
+		if shape.Body != sensor.Body && !shape.Sensor && shape.Body.Type() != cm.Static {
+			occupied = true
+		}
+	})
+	return occupied
+}
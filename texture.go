@@ -0,0 +1,49 @@
+package ebitencm
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/setanarut/cm"
+	"github.com/setanarut/v"
+)
+
+// TextureSpace selects the coordinate space texture coordinates are computed in
+type TextureSpace int
+
+const (
+	// TextureWorld pins the texture to the world, shapes move over it
+	TextureWorld TextureSpace = iota
+	// TextureLocal pins the texture to the body, it moves and rotates with the body
+	TextureLocal
+)
+
+// Texture maps a repeating image onto filled shapes.
+//
+// The texture is multiplied by the fill color.
+type Texture struct {
+	// Image or sub-image repeated over the shape
+	Image *ebiten.Image
+	// Space of the texture coordinates
+	Space TextureSpace
+	// Scale is the size of one image pixel in world units, zero is treated as 1
+	Scale float64
+	// Offset of the texture origin in world or body-local coordinates
+	Offset v.Vec
+}
+
+// mapVertices sets the source coordinates of the world space vertices
+func (t *Texture) mapVertices(vs []ebiten.Vertex, body *cm.Body) {
+	scale := t.Scale
+	if scale == 0 {
+		scale = 1
+	}
+	origin := t.Image.Bounds().Min
+	for i := range vs {
+		p := v.Vec{X: float64(vs[i].DstX), Y: float64(vs[i].DstY)}
+		if t.Space == TextureLocal && body != nil {
+			p = body.WorldToLocal(p)
+		}
+		p = p.Sub(t.Offset).Scale(1 / scale)
+		vs[i].SrcX = float32(p.X) + float32(origin.X)
+		vs[i].SrcY = float32(p.Y) + float32(origin.Y)
+	}
+}
@@ -0,0 +1,204 @@
+package ebitencm
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/setanarut/cm"
+)
+
+// Theme preset names
+const (
+	ThemeDark         = "dark"
+	ThemeLight        = "light"
+	ThemeChipmunk     = "chipmunk"
+	ThemeHighContrast = "high-contrast"
+	ThemeDeuteranopia = "deuteranopia"
+	ThemeProtanopia   = "protanopia"
+)
+
+var themePresets = map[string]func() *Theme{
+	ThemeDark:         DarkTheme,
+	ThemeLight:        LightTheme,
+	ThemeChipmunk:     ChipmunkTheme,
+	ThemeHighContrast: HighContrastTheme,
+	ThemeDeuteranopia: DeuteranopiaTheme,
+	ThemeProtanopia:   ProtanopiaTheme,
+}
+
+// ThemePresets returns the sorted preset names
+func ThemePresets() []string {
+	names := make([]string, 0, len(themePresets))
+	for name := range themePresets {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// ThemePreset returns a new theme of the named preset
+func ThemePreset(name string) (*Theme, error) {
+	preset, ok := themePresets[name]
+	if !ok {
+		return nil, fmt.Errorf("ebitencm: unknown theme preset %q", name)
+	}
+	return preset(), nil
+}
+
+// SetThemePreset replaces the drawer theme with the named preset
+func (d *Drawer) SetThemePreset(name string) error {
+	t, err := ThemePreset(name)
+	if err != nil {
+		return err
+	}
+	d.Theme = t
+	return nil
+}
+
+// setConstraintColors sets all constraint dot and segment colors
+func (t *Theme) setConstraintColors(clr cm.FColor) {
+	t.ConstraintDampedSpringDot = clr
+	t.ConstraintDampedSpringSegment = clr
+	t.ConstraintGrooveJointDot = clr
+	t.ConstraintGrooveJointSegment = clr
+	t.ConstraintPinJointDot = clr
+	t.ConstraintPinJointSegment = clr
+	t.ConstraintPivotJointDot = clr
+	t.ConstraintSlideJointDot = clr
+	t.ConstraintSlideJointSegment = clr
+}
+
+// DarkTheme is the DefaultTheme, for dark backgrounds
+func DarkTheme() *Theme {
+	return DefaultTheme()
+}
+
+// LightTheme is for light backgrounds
+func LightTheme() *Theme {
+	t := DefaultTheme()
+	t.CollisionNormal = cm.FColor{0.85, 0.1, 0.1, 1}
+	t.setConstraintColors(cm.FColor{0, 0.45, 0.45, 1})
+	t.ConstraintDampedSpringDot = cm.FColor{0.75, 0.3, 0.1, 1}
+	t.ConstraintDampedSpringSegment = cm.FColor{0.75, 0.3, 0.1, 1}
+	t.DynamicBodyFill = cm.FColor{0.35, 0.55, 0.9, 1}
+	t.DynamicBodyIdleFill = cm.FColor{0.75, 0.75, 0.8, 1}
+	t.DynamicBodySleepingFill = cm.FColor{0.6, 0.6, 0.65, 1}
+	t.DynamicBodyStroke = cm.FColor{0.1, 0.15, 0.3, 1}
+	t.SensorFill = cm.FColor{0.1, 0.6, 0.3, 0.2}
+	t.SensorStroke = cm.FColor{0.1, 0.5, 0.25, 1}
+	t.StaticBodyFill = cm.FColor{0.45, 0.4, 0.35, 1}
+	t.StaticBodyStroke = cm.FColor{0.2, 0.15, 0.1, 1}
+	t.ShadowColor = cm.FColor{0, 0, 0, 0.3}
+	t.GlowColor = cm.FColor{0.2, 0.4, 1, 0.6}
+	t.QueryHit = cm.FColor{0.85, 0.2, 0.1, 1}
+	t.QueryStroke = cm.FColor{0, 0, 0, 0.6}
+	t.Trail = cm.FColor{0.85, 0.45, 0, 1}
+	t.Trajectory = cm.FColor{0, 0, 0, 0.8}
+	t.GridAxisX = cm.FColor{0.8, 0.1, 0.1, 0.8}
+	t.GridAxisY = cm.FColor{0.1, 0.55, 0.1, 0.8}
+	t.GridLabel = cm.FColor{0, 0, 0, 0.7}
+	t.GridMajor = cm.FColor{0, 0, 0, 0.25}
+	t.GridMinor = cm.FColor{0, 0, 0, 0.08}
+	t.HUDBackground = cm.FColor{1, 1, 1, 0.75}
+	t.HUDText = cm.FColor{0, 0, 0, 1}
+	t.GraphBackground = cm.FColor{1, 1, 1, 0.75}
+	t.GraphLine = cm.FColor{0, 0.45, 0.7, 1}
+	t.GraphText = cm.FColor{0, 0, 0, 1}
+	return t
+}
+
+// ChipmunkTheme is the look of the Chipmunk2D demos. Dynamic bodies are colored by hash.
+func ChipmunkTheme() *Theme {
+	t := DefaultTheme()
+	outline := cm.FColor{200.0 / 255, 210.0 / 255, 230.0 / 255, 1}
+	t.CollisionNormal = cm.FColor{1, 0, 0, 1}
+	t.setConstraintColors(cm.FColor{0, 0.75, 0, 1})
+	t.DynamicBodyColorMode = ColorModeHash
+	t.DynamicBodyIdleFill = cm.FColor{0.66, 0.66, 0.66, 1}
+	t.DynamicBodySleepingFill = cm.FColor{0.2, 0.2, 0.2, 1}
+	t.DynamicBodyStroke = outline
+	t.StaticBodyFill = cm.FColor{0.5, 0.5, 0.5, 1}
+	t.StaticBodyStroke = outline
+	t.QueryHit = cm.FColor{1, 0, 0, 1}
+	t.QueryStroke = cm.FColor{outline.R, outline.G, outline.B, 0.6}
+	t.Trail = cm.FColor{1, 1, 0, 1}
+	t.Trajectory = cm.FColor{outline.R, outline.G, outline.B, 0.8}
+	t.GridLabel = cm.FColor{outline.R, outline.G, outline.B, 0.7}
+	t.GridMajor = cm.FColor{outline.R, outline.G, outline.B, 0.25}
+	t.GridMinor = cm.FColor{outline.R, outline.G, outline.B, 0.08}
+	t.GraphLine = cm.FColor{0, 0.75, 0, 1}
+	return t
+}
+
+// HighContrastTheme uses saturated colors and white outlines on dark backgrounds
+func HighContrastTheme() *Theme {
+	t := DefaultTheme()
+	white := cm.FColor{1, 1, 1, 1}
+	t.CollisionNormal = cm.FColor{1, 0, 1, 1}
+	t.setConstraintColors(cm.FColor{0, 1, 1, 1})
+	t.DynamicBodyFill = cm.FColor{1, 0.85, 0, 1}
+	t.DynamicBodyIdleFill = cm.FColor{0.6, 0.6, 0.6, 1}
+	t.DynamicBodySleepingFill = cm.FColor{0.35, 0.35, 0.35, 1}
+	t.DynamicBodyStroke = white
+	t.SensorFill = cm.FColor{0, 1, 0, 0.3}
+	t.SensorStroke = cm.FColor{0, 1, 0, 1}
+	t.StaticBodyFill = cm.FColor{0, 0, 0, 1}
+	t.StaticBodyStroke = white
+	t.QueryHit = cm.FColor{1, 0, 1, 1}
+	t.QueryStroke = cm.FColor{1, 1, 1, 0.6}
+	t.Trail = cm.FColor{1, 0.85, 0, 1}
+	t.Trajectory = cm.FColor{1, 1, 1, 1}
+	t.GridAxisX = cm.FColor{1, 0, 0, 1}
+	t.GridAxisY = cm.FColor{0, 1, 0, 1}
+	t.GridLabel = white
+	t.GridMajor = cm.FColor{1, 1, 1, 0.5}
+	t.GridMinor = cm.FColor{1, 1, 1, 0.2}
+	t.HUDBackground = cm.FColor{0, 0, 0, 0.9}
+	t.GraphBackground = cm.FColor{0, 0, 0, 0.9}
+	t.GraphLine = cm.FColor{0, 1, 1, 1}
+	return t
+}
+
+// DeuteranopiaTheme uses blue and orange of the Okabe-Ito palette, readable with red-green color blindness
+func DeuteranopiaTheme() *Theme {
+	t := DefaultTheme()
+	t.CollisionNormal = cm.FColor{0.941, 0.894, 0.259, 1}    // yellow
+	t.setConstraintColors(cm.FColor{0.337, 0.706, 0.914, 1}) // sky blue
+	t.DynamicBodyFill = cm.FColor{0, 0.447, 0.698, 1}        // blue
+	t.DynamicBodyIdleFill = cm.FColor{0.6, 0.6, 0.6, 1}      // gray
+	t.DynamicBodySleepingFill = cm.FColor{0.4, 0.4, 0.4, 1}  // dark gray
+	t.DynamicBodyStroke = cm.FColor{0.902, 0.624, 0, 1}      // orange
+	t.SensorFill = cm.FColor{0.8, 0.475, 0.655, 0.25}        // reddish purple
+	t.SensorStroke = cm.FColor{0.8, 0.475, 0.655, 1}         // reddish purple
+	t.StaticBodyFill = cm.FColor{0.835, 0.369, 0, 1}         // vermillion
+	t.StaticBodyStroke = cm.FColor{0.902, 0.624, 0, 1}       // orange
+	t.GlowColor = cm.FColor{0.337, 0.706, 0.914, 0.8}
+	t.QueryHit = cm.FColor{0.941, 0.894, 0.259, 1}    // yellow
+	t.Trail = cm.FColor{0.902, 0.624, 0, 1}           // orange
+	t.GridAxisX = cm.FColor{0.902, 0.624, 0, 0.8}     // orange
+	t.GridAxisY = cm.FColor{0.337, 0.706, 0.914, 0.8} // sky blue
+	t.GraphLine = cm.FColor{0.337, 0.706, 0.914, 1}   // sky blue
+	return t
+}
+
+// ProtanopiaTheme uses blue and yellow of the Okabe-Ito palette and avoids red, which appears dark with protanopia
+func ProtanopiaTheme() *Theme {
+	t := DefaultTheme()
+	t.CollisionNormal = cm.FColor{1, 1, 1, 1}                // white
+	t.setConstraintColors(cm.FColor{0.337, 0.706, 0.914, 1}) // sky blue
+	t.DynamicBodyFill = cm.FColor{0, 0.447, 0.698, 1}        // blue
+	t.DynamicBodyIdleFill = cm.FColor{0.6, 0.6, 0.6, 1}      // gray
+	t.DynamicBodySleepingFill = cm.FColor{0.4, 0.4, 0.4, 1}  // dark gray
+	t.DynamicBodyStroke = cm.FColor{0.941, 0.894, 0.259, 1}  // yellow
+	t.SensorFill = cm.FColor{0, 0.62, 0.451, 0.25}           // bluish green
+	t.SensorStroke = cm.FColor{0, 0.62, 0.451, 1}            // bluish green
+	t.StaticBodyFill = cm.FColor{0.902, 0.624, 0, 1}         // orange
+	t.StaticBodyStroke = cm.FColor{0.941, 0.894, 0.259, 1}   // yellow
+	t.GlowColor = cm.FColor{0.337, 0.706, 0.914, 0.8}
+	t.QueryHit = cm.FColor{0.941, 0.894, 0.259, 1}    // yellow
+	t.Trail = cm.FColor{0.902, 0.624, 0, 1}           // orange
+	t.GridAxisX = cm.FColor{0.941, 0.894, 0.259, 0.8} // yellow
+	t.GridAxisY = cm.FColor{0.337, 0.706, 0.914, 0.8} // sky blue
+	t.GraphLine = cm.FColor{0.337, 0.706, 0.914, 1}   // sky blue
+	return t
+}
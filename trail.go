@@ -0,0 +1,123 @@
+package ebitencm
+
+import (
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/setanarut/cm"
+	"github.com/setanarut/v"
+)
+
+// Trail is a fading polyline of the past positions of a point on a body
+type Trail struct {
+	// LocalPoint is the recorded point in body-local coordinates
+	LocalPoint v.Vec
+	// Length is the maximum number of samples
+	Length int
+	// Interval is the number of RecordTrails calls between samples
+	Interval int
+	// Color of the newest sample, older samples fade out. nil uses Theme.Trail
+	Color *cm.FColor
+	// StrokeWidth of the polyline. 0 uses DrawingOptions.TrailStrokeWidth
+	StrokeWidth float32
+
+	samples []v.Vec
+	head    int
+	count   int
+	tick    int
+}
+
+// NewTrail returns a trail of the body position with the maximum number of samples, sampled at every call
+func NewTrail(length int) *Trail {
+	return &Trail{Length: length, Interval: 1}
+}
+
+// Clear removes all samples
+func (t *Trail) Clear() {
+	t.head, t.count, t.tick = 0, 0, 0
+}
+
+// record samples the world position of the trail point
+func (t *Trail) record(body *cm.Body) {
+	if t.Length <= 0 {
+		return
+	}
+	if len(t.samples) != t.Length {
+		t.samples = make([]v.Vec, t.Length)
+		t.Clear()
+	}
+	t.tick++
+	if t.tick < max(t.Interval, 1) && t.count > 0 {
+		return
+	}
+	t.tick = 0
+	t.samples[t.head] = body.Transform().Apply(t.LocalPoint)
+	t.head = (t.head + 1) % len(t.samples)
+	t.count = min(t.count+1, len(t.samples))
+}
+
+// at returns the i-th sample from the oldest
+func (t *Trail) at(i int) v.Vec {
+	n := len(t.samples)
+	return t.samples[(t.head-t.count+i+n)%n]
+}
+
+// SetBodyTrail attaches the trail to the body. nil removes the trail.
+func (d *Drawer) SetBodyTrail(body *cm.Body, trail *Trail) {
+	if trail == nil {
+		delete(d.bodyTrails, body)
+		return
+	}
+	if d.bodyTrails == nil {
+		d.bodyTrails = make(map[*cm.Body]*Trail)
+	}
+	d.bodyTrails[body] = trail
+}
+
+// BodyTrail returns the trail of the body or nil
+func (d *Drawer) BodyTrail(body *cm.Body) *Trail {
+	return d.bodyTrails[body]
+}
+
+// RecordTrails samples all trails. Call it after every Space.Step.
+func (d *Drawer) RecordTrails() {
+	for body, t := range d.bodyTrails {
+		t.record(body)
+	}
+}
+
+// drawTrails draws the trails of the space bodies in space order
+func (d *Drawer) drawTrails(space *cm.Space) {
+	if len(d.bodyTrails) == 0 {
+		return
+	}
+	space.EachDynamicBody(func(body *cm.Body) {
+		if t, ok := d.bodyTrails[body]; ok {
+			d.drawTrail(t)
+		}
+	})
+}
+
+// drawTrail draws the trail segments with alpha increasing from the oldest to the newest sample.
+// A trail whose Length changed since the last sample is not drawn until it is recorded again.
+func (d *Drawer) drawTrail(t *Trail) {
+	if t.count < 2 || len(t.samples) != t.Length {
+		return
+	}
+	clr := d.Theme.Trail
+	if t.Color != nil {
+		clr = *t.Color
+	}
+	w := t.StrokeWidth
+	if w == 0 {
+		w = d.DrawingOptions.TrailStrokeWidth
+	}
+	prev := t.at(0)
+	for i := 1; i < t.count; i++ {
+		p := t.at(i)
+		a := clr.A * float32(i) / float32(t.count-1)
+		path := vector.Path{}
+		path.MoveTo(float32(prev.X), float32(prev.Y))
+		path.LineTo(float32(p.X), float32(p.Y))
+		d.strokePath(d.Screen, path, clr.R, clr.G, clr.B, a, w)
+		prev = p
+	}
+}
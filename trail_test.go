@@ -0,0 +1,52 @@
+package ebitencm
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/setanarut/cm"
+	"github.com/setanarut/v"
+)
+
+// trailStep sets the trail length and interval and records the body at x
+type trailStep struct {
+	length, interval int
+	x                float64
+}
+
+func TestTrailRecord(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []trailStep
+		// want are the x of the samples from the oldest
+		want []float64
+	}{
+		{"empty", nil, nil},
+		{"partial", []trailStep{{3, 1, 1}, {3, 1, 2}}, []float64{1, 2}},
+		{"full", []trailStep{{3, 1, 1}, {3, 1, 2}, {3, 1, 3}}, []float64{1, 2, 3}},
+		{"wrapped", []trailStep{{3, 1, 1}, {3, 1, 2}, {3, 1, 3}, {3, 1, 4}, {3, 1, 5}}, []float64{3, 4, 5}},
+		{"grow", []trailStep{{2, 1, 1}, {2, 1, 2}, {2, 1, 3}, {4, 1, 4}, {4, 1, 5}}, []float64{4, 5}},
+		{"shrink", []trailStep{{4, 1, 1}, {4, 1, 2}, {4, 1, 3}, {2, 1, 4}, {2, 1, 5}, {2, 1, 6}}, []float64{5, 6}},
+		{"zero length", []trailStep{{3, 1, 1}, {3, 1, 2}, {0, 1, 3}}, []float64{1, 2}},
+		{"interval", []trailStep{{3, 2, 1}, {3, 2, 2}, {3, 2, 3}, {3, 2, 4}, {3, 2, 5}}, []float64{1, 3, 5}},
+		{"zero interval", []trailStep{{3, 0, 1}, {3, 0, 2}}, []float64{1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := cm.NewBody(1, 1)
+			trail := NewTrail(0)
+			for _, s := range tt.steps {
+				trail.Length, trail.Interval = s.length, s.interval
+				body.SetPosition(v.Vec{X: s.x})
+				trail.record(body)
+			}
+			var got []float64
+			for i := range trail.count {
+				got = append(got, trail.at(i).X)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("samples %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package ebitencm
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/setanarut/cm"
+	"github.com/setanarut/v"
+)
+
+// Trajectory is a predicted ballistic path
+type Trajectory struct {
+	// Points of the path, starting at the body position
+	Points []v.Vec
+	// Hit is the first shape hit by the path. Hit.Shape is nil if nothing was hit.
+	Hit cm.SegmentQueryInfo
+}
+
+// PredictTrajectory simulates the ballistic path of the body launched with the velocity
+// under the gravity and damping of the body space for steps of dt.
+// Collisions are ignored. With stopAtHit the path ends at the first shape of another body
+// hit by a segment query.
+func PredictTrajectory(body *cm.Body, velocity v.Vec, steps int, dt float64, stopAtHit bool) *Trajectory {
+	space := body.Space
+	gravity, damping := v.Vec{}, 1.0
+	if space != nil {
+		gravity, damping = space.Gravity, math.Pow(space.Damping, dt)
+	} else {
+		stopAtHit = false
+	}
+	steps = max(steps, 0)
+	pos := body.Position()
+	t := &Trajectory{Points: make([]v.Vec, 1, steps+1)}
+	t.Points[0] = pos
+	for range steps {
+		velocity = velocity.Scale(damping).Add(gravity.Scale(dt))
+		next := pos.Add(velocity.Scale(dt))
+		if stopAtHit {
+			if hit := segmentQueryOther(space, body, pos, next); hit.Shape != nil {
+				t.Hit = hit
+				t.Points = append(t.Points, hit.Point)
+				return t
+			}
+		}
+		t.Points = append(t.Points, next)
+		pos = next
+	}
+	return t
+}
+
+// segmentQueryOther returns the first hit of the segment query ignoring the shapes of the body
+func segmentQueryOther(space *cm.Space, body *cm.Body, a, b v.Vec) cm.SegmentQueryInfo {
+	first := cm.SegmentQueryInfo{Alpha: 1}
+	space.SegmentQuery(a, b, 0, cm.ShapeFilterAll, func(shape *cm.Shape, point, normal v.Vec, alpha float64, _ any) {
+		if shape.Body != body && !shape.Sensor && alpha < first.Alpha {
+			first = cm.SegmentQueryInfo{Shape: shape, Point: point, Normal: normal, Alpha: alpha}
+		}
+	}, nil)
+	return first
+}
+
+// DrawTrajectory draws the trajectory as a dotted line with a marker and the surface normal at the hit point
+func (d *Drawer) DrawTrajectory(t *Trajectory, screen *ebiten.Image) {
+	d.Screen = screen
+	clr, w := d.Theme.Trajectory, d.DrawingOptions.TrajectoryStrokeWidth
+	d.strokeDash = DottedLine(float64(w) * 3)
+	d.drawPolyline(t.Points, false, clr, w)
+	d.strokeDash = Dash{}
+	if t.Hit.Shape == nil {
+		return
+	}
+	p := t.Hit.Point
+	r := float64(w) * 3
+	d.drawRing(p, r, clr, w)
+	d.drawSegment(p.Add(v.Vec{X: -r, Y: -r}), p.Add(v.Vec{X: r, Y: r}), clr, w)
+	d.drawSegment(p.Add(v.Vec{X: -r, Y: r}), p.Add(v.Vec{X: r, Y: -r}), clr, w)
+	d.drawArrow(p, p.Add(t.Hit.Normal.Scale(d.DrawingOptions.CollisionNormalLength)), clr, w)
+}
@@ -0,0 +1,136 @@
+package ebitencm
+
+import (
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/setanarut/cm"
+	"github.com/setanarut/v"
+)
+
+var (
+	vecType     = reflect.TypeFor[v.Vec]()
+	float64Type = reflect.TypeFor[float64]()
+)
+
+// LerpColor linearly interpolates between the colors, t [0-1]
+func LerpColor(a, b cm.FColor, t float64) cm.FColor {
+	f := float32(t)
+	return cm.FColor{
+		R: a.R + (b.R-a.R)*f,
+		G: a.G + (b.G-a.G)*f,
+		B: a.B + (b.B-a.B)*f,
+		A: a.A + (b.A-a.A)*f,
+	}
+}
+
+// LerpTheme returns a new theme interpolated between a and b, t [0-1].
+//
+// Colors, vectors and numbers are interpolated. Other fields such as
+// DynamicBodyColorMode and Gradient switch from a to b at t = 0.5.
+func LerpTheme(a, b *Theme, t float64) *Theme {
+	out := *a
+	ra, rb, ro := reflect.ValueOf(a).Elem(), reflect.ValueOf(b).Elem(), reflect.ValueOf(&out).Elem()
+	for i := range ro.NumField() {
+		fa, fb, fo := ra.Field(i), rb.Field(i), ro.Field(i)
+		switch fo.Type() {
+		case fcolorType:
+			fo.Set(reflect.ValueOf(LerpColor(fa.Interface().(cm.FColor), fb.Interface().(cm.FColor), t)))
+		case vecType:
+			fo.Set(reflect.ValueOf(fa.Interface().(v.Vec).Lerp(fb.Interface().(v.Vec), t)))
+		case float64Type:
+			fo.SetFloat(fa.Float() + (fb.Float()-fa.Float())*t)
+		default:
+			if t >= 0.5 {
+				fo.Set(fb)
+			}
+		}
+	}
+	return &out
+}
+
+// WithOpacity returns a copy of the theme with all color alphas multiplied by alpha [0-1]
+func (t *Theme) WithOpacity(alpha float32) *Theme {
+	out := *t
+	ro := reflect.ValueOf(&out).Elem()
+	for i := range ro.NumField() {
+		if f := ro.Field(i); f.Type() == fcolorType {
+			c := f.Addr().Interface().(*cm.FColor)
+			c.A *= alpha
+		}
+	}
+	return &out
+}
+
+// EaseInOut is a smooth sine easing for ThemeTween
+func EaseInOut(t float64) float64 {
+	return (1 - math.Cos(t*math.Pi)) / 2
+}
+
+// ThemeTween animates the drawer theme from From to To
+type ThemeTween struct {
+	From, To *Theme
+	Duration time.Duration
+	// Ease maps the linear progress [0-1] to the interpolation factor. nil is linear.
+	Ease func(t float64) float64
+	// Yoyo returns to From after reaching To, for flashes
+	Yoyo bool
+
+	start time.Time
+}
+
+// At returns the theme after the elapsed time and whether the tween is finished
+func (tw *ThemeTween) At(elapsed time.Duration) (*Theme, bool) {
+	p := 1.0
+	if tw.Duration > 0 {
+		p = min(float64(elapsed)/float64(tw.Duration), 1)
+	}
+	if tw.Yoyo {
+		p = 1 - math.Abs(2*p-1)
+	}
+	done := elapsed >= tw.Duration
+	if done {
+		if tw.Yoyo {
+			return tw.From, true
+		}
+		return tw.To, true
+	}
+	if tw.Ease != nil {
+		p = tw.Ease(p)
+	}
+	return LerpTheme(tw.From, tw.To, p), false
+}
+
+// TweenTheme starts the theme tween. The drawer advances it at every DrawSpace and
+// sets Drawer.Theme to the final theme when done. nil stops the current tween.
+//
+// Changes made to Drawer.Theme during the tween are overwritten.
+func (d *Drawer) TweenTheme(tw *ThemeTween) {
+	if tw != nil {
+		tw.start = time.Now()
+	}
+	d.themeTween = tw
+}
+
+// FadeTheme smoothly changes the drawer theme to the theme in duration
+func (d *Drawer) FadeTheme(to *Theme, duration time.Duration) {
+	d.TweenTheme(&ThemeTween{From: d.Theme, To: to, Duration: duration, Ease: EaseInOut})
+}
+
+// FlashTheme blends the drawer theme to the flash theme and back in duration
+func (d *Drawer) FlashTheme(flash *Theme, duration time.Duration) {
+	d.TweenTheme(&ThemeTween{From: d.Theme, To: flash, Duration: duration, Yoyo: true})
+}
+
+// updateThemeTween sets Drawer.Theme from the running tween
+func (d *Drawer) updateThemeTween() {
+	if d.themeTween == nil {
+		return
+	}
+	t, done := d.themeTween.At(time.Since(d.themeTween.start))
+	d.Theme = t
+	if done {
+		d.themeTween = nil
+	}
+}